@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ResourceAttributes returns the options in the struct obj points to which
+// have an `otel:""` struct tag, keyed by the tag, for use as OpenTelemetry
+// resource attributes. For example, a field tagged
+// `otel:"service.version"` gives the attribute `service.version`. Secret
+// options are always left out, as are nil pointers.
+func ResourceAttributes(obj any) (map[string]string, error) {
+	v, err := structValue(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := map[string]string{}
+	for _, fi := range structFields(v) {
+		key := fi.f.Tag.Get("otel")
+		if key == "" || fi.secret {
+			continue
+		}
+
+		if fi.v.Kind() == reflect.Pointer && fi.v.IsNil() {
+			continue
+		}
+
+		val, err := formatValue(fi.v)
+		if err != nil {
+			return nil, fmt.Errorf(errorWritingConfig, fi.name, err)
+		}
+		attrs[key] = val
+	}
+
+	return attrs, nil
+}
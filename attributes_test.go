@@ -0,0 +1,28 @@
+package config_test
+
+import (
+	"maps"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestResourceAttributes(t *testing.T) {
+	conf := struct {
+		Name     string  `otel:"service.name"`
+		Version  string  `otel:"service.version"`
+		Token    string  `config:"token,secret" otel:"service.token"`
+		Region   *string `otel:"cloud.region"`
+		Replicas int
+	}{Name: "api", Version: "1.4.2", Token: "hunter2", Replicas: 3}
+
+	attrs, err := config.ResourceAttributes(&conf)
+	if err != nil {
+		t.Fatalf("failed to get attributes: %v", err)
+	}
+
+	expected := map[string]string{"service.name": "api", "service.version": "1.4.2"}
+	if !maps.Equal(attrs, expected) {
+		t.Fatalf("expected %v, found %v", expected, attrs)
+	}
+}
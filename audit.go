@@ -0,0 +1,72 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithAuditFile makes a successful read write the effective value of every
+// option to the file at path, in the format read by [config.Parse], so it's
+// known exactly what a process was running with, for example after an
+// incident. The file starts with a comment giving where the options were read
+// from and when. The values of secret options are replaced with `******`.
+//
+// The file is replaced atomically, by writing a new file beside it and
+// renaming it over the old one, so it is never seen half written. If it can't
+// be written, an error of kind KindIO is returned, although the struct has
+// been read.
+func WithAuditFile(path string) Option {
+	return func(o *options) {
+		o.auditPath = path
+	}
+}
+
+// writeAudit writes the effective values in s, with those of secret fields
+// hidden, to the file at path.
+func (s *readState) writeAudit(path string, fields []fieldInfo) error {
+	vals := maps.Clone(s.vals)
+	for _, fi := range fields {
+		if _, ok := vals[fi.name]; ok && fi.secret {
+			vals[fi.name] = "******"
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# effective configuration read from %v at %v\n", s.path, time.Now().Format(time.RFC3339))
+	if err := WriteValues(&b, vals); err != nil {
+		return &Error{File: path, Kind: KindIO, Err: err}
+	}
+
+	if err := writeFileAtomic(path, b.Bytes()); err != nil {
+		return &Error{File: path, Kind: KindIO, Err: err}
+	}
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with one holding data.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestWithAuditFile(t *testing.T) {
+	env := config.EnvMap{"AUDIT_PORT": "9090"}
+	path := filepath.Join(t.TempDir(), "effective.conf")
+
+	var conf struct {
+		Host     string `config:"audit_host"`
+		Port     int    `config:"audit_port"`
+		Password string `config:"audit_password,secret"`
+	}
+	input := "audit_host = localhost\naudit_port = 80\naudit_password = hunter2\nextra = '# not a comment'"
+	if err := config.Read("<input>", strings.NewReader(input), &conf, config.WithAuditFile(path),
+		config.WithEnvironment(env)); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read audit file: %v", err)
+	}
+
+	header, body, _ := strings.Cut(string(data), "\n")
+	if !strings.HasPrefix(header, "# effective configuration read from <input> at ") {
+		t.Fatalf("expected a header, found %q", header)
+	}
+
+	expected := "audit_host = localhost\naudit_password = ******\naudit_port = 9090\nextra = \"# not a comment\"\n"
+	if body != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, body)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected only the audit file to be left, found %v: %v", entries, err)
+	}
+
+	err = config.Read("<input>", strings.NewReader(input), &conf,
+		config.WithAuditFile(filepath.Join(path, "missing", "effective.conf")), config.WithEnvironment(env))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Kind != config.KindIO {
+		t.Fatalf("expected an IO error, found %v", err)
+	}
+}
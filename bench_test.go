@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// These benchmarks cover the work done when reading a config. Times depend on
+// the machine, so to check a change made for performance, compare it against
+// the commit before it on the same machine:
+//
+//	git stash
+//	go test -run '^$' -bench . -benchmem -count 10 > old.txt
+//	git stash pop
+//	go test -run '^$' -bench . -benchmem -count 10 > new.txt
+//	benchstat old.txt new.txt
+//
+// benchstat is golang.org/x/perf/cmd/benchstat. Allocations don't depend on
+// the machine, so TestAllocs fails if the hot paths allocate more than they
+// did when it was written, which with Go 1.27 was about 9 allocations per key
+// for Parse, 11 per field for Read and 2 for a whole Bind. Lower its limits
+// along with a change which allocates less.
+
+// benchConfig returns a config file setting n options, named field0 to
+// field<n-1>.
+func benchConfig(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i += 1 {
+		fmt.Fprintf(&b, "field%d = value %d # comment\n", i, i)
+	}
+	return b.String()
+}
+
+// benchStruct returns a pointer to a new struct with n string fields, named
+// Field0 to Field<n-1>.
+func benchStruct(n int) any {
+	fields := make([]reflect.StructField, n)
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: reflect.TypeFor[string](),
+		}
+	}
+	return reflect.New(reflect.StructOf(fields)).Interface()
+}
+
+var benchSizes = []int{1, 10, 100, 1000}
+
+func BenchmarkParse(b *testing.B) {
+	for _, n := range benchSizes {
+		input := benchConfig(n)
+		b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i += 1 {
+				if _, err := Parse("<bench>", strings.NewReader(input)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	for _, n := range benchSizes {
+		input := benchConfig(n)
+		obj := benchStruct(n)
+		b.Run(fmt.Sprintf("fields=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i += 1 {
+				if err := Read("<bench>", strings.NewReader(input), obj); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReadEnvOverride(b *testing.B) {
+	const n = 100
+	for i := 0; i < n; i += 1 {
+		b.Setenv(fmt.Sprintf("FIELD%d", i), "from the environment")
+	}
+
+	input := benchConfig(n)
+	obj := benchStruct(n)
+	for i := 0; i < b.N; i += 1 {
+		if err := Read("<bench>", strings.NewReader(input), obj); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkToSnakeCase(b *testing.B) {
+	names := []string{"Port", "ListenAddress", "MaxIdleConnectionsPerHost"}
+	for _, name := range names {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i += 1 {
+				toSnakeCase(name)
+			}
+		})
+	}
+}
+
+func BenchmarkBind(b *testing.B) {
+	const n = 100
+	vals, err := Parse("<bench>", strings.NewReader(benchConfig(n)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	obj := benchStruct(n)
+	binder, err := NewBinder(obj)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i += 1 {
+		if err := binder.Bind(vals, obj); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// allocsPerKey is the most allocations Parse and Read may make for each key
+// or field before TestAllocs fails.
+var allocsPerKey = map[string]float64{
+	"Parse": 9,
+	"Read":  11,
+}
+
+func TestAllocs(t *testing.T) {
+	for _, n := range []int{100, 1000} {
+		input := benchConfig(n)
+		obj := benchStruct(n)
+		allocs := map[string]float64{
+			"Parse": testing.AllocsPerRun(10, func() {
+				if _, err := Parse("<bench>", strings.NewReader(input)); err != nil {
+					t.Fatal(err)
+				}
+			}),
+			"Read": testing.AllocsPerRun(10, func() {
+				err := Read("<bench>", strings.NewReader(input), obj, WithEnvironment(EnvMap{}))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}),
+		}
+
+		for name, limit := range allocsPerKey {
+			if perKey := allocs[name] / float64(n); perKey > limit {
+				t.Errorf("%v with %v keys made %.1f allocations per key, more than %v",
+					name, n, perKey, limit)
+			}
+		}
+	}
+
+	vals, err := Parse("<bench>", strings.NewReader(benchConfig(100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj := benchStruct(100)
+	binder, err := NewBinder(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := binder.Bind(vals, obj); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 2 {
+		t.Errorf("Bind made %v allocations, more than 2", allocs)
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Binder sets the fields of structs of a single type from [Values], for
+// programs which bind the same type many times. It works out how to bind the
+// type once, when it is created, and is safe for concurrent use.
+//
+// Unlike [config.Read], a Binder doesn't look at environment variables: the
+// values given to Bind are used as they are.
+type Binder struct {
+	typ    reflect.Type
+	fields []fieldInfo
+	opts   *options
+}
+
+// NewBinder returns a Binder for the type of struct prototype points to.
+// Only the options affecting errors, like [config.WithErrorFormatter], apply
+// to a Binder.
+func NewBinder(prototype any, opts ...Option) (*Binder, error) {
+	v, err := structValue(prototype)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Binder{
+		typ:    v.Type(),
+		fields: typeFields(v.Type()),
+		opts:   newOptions(opts),
+	}, nil
+}
+
+// Bind sets the fields of the struct obj points to from vals, in the same way
+// [config.Read] would. obj must point to a struct of the Binder's type. vals
+// isn't modified, so the same Values can be bound by several goroutines at
+// once.
+func (b *Binder) Bind(vals Values, obj any) error {
+	v, err := structValue(obj)
+	if err != nil {
+		return err
+	}
+
+	if v.Type() != b.typ {
+		return fmt.Errorf(
+			"config: Binder for %v was given a %v",
+			b.typ, v.Type(),
+		)
+	}
+
+	s := readState{
+		path:   "<values>",
+		vals:   vals,
+		groups: groupSet{},
+	}
+	return b.opts.finish(s.bind(v, b.fields))
+}
+
+// Section is a struct read by [config.BindAll] from the options whose keys
+// start with Prefix and a dot, as [config.ReadSection] reads it.
+type Section struct {
+	Prefix string
+	// Obj points to the struct to read.
+	Obj any
+}
+
+// BindAll reads each of objs from vals, which is parsed once with
+// [config.Parse] or [config.Document.Values], so that a program made of
+// several components can read each component's struct from one file. Each of
+// objs is either a pointer to a struct, which is read as [config.ReadSection]
+// reads it with an empty prefix, or a [Section] to read it from the options
+// under a prefix. Like a [Binder], BindAll doesn't look at environment
+// variables. Every struct is read even if an earlier one fails. Keys in
+// vals which none of the structs have are errors of kind KindUnknown, found
+// once all the structs are read. Errors refer to the file `<values>`, and are
+// returned joined.
+func BindAll(vals Values, objs ...any) error {
+	var errs []error
+	bound := map[string]bool{}
+	for _, obj := range objs {
+		prefix := ""
+		if s, ok := obj.(Section); ok {
+			prefix, obj = s.Prefix, s.Obj
+		}
+
+		v, err := structValue(obj)
+		if err != nil {
+			return err
+		}
+
+		for _, fi := range sectionFields(v.Type(), prefix) {
+			bound[fi.name] = true
+		}
+
+		if err := ReadSection(vals, prefix, obj, WithPrecedence(LayerFile)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var keys []string
+	for key := range vals {
+		if !bound[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		errs = append(errs, newError("<values>", key, KindUnknown, fmt.Errorf(unknownKey, key)))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+type tenantConfig struct {
+	Name     string
+	MaxConns int
+	Debug    bool `config:"debug,optional"`
+}
+
+func TestBinder(t *testing.T) {
+	b, err := config.NewBinder(&tenantConfig{})
+	if err != nil {
+		t.Fatalf("failed to create binder: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 100; i += 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vals := config.Values{
+				"name":      fmt.Sprintf("tenant%d", i),
+				"max_conns": strconv.Itoa(i),
+			}
+
+			var conf tenantConfig
+			if err := b.Bind(vals, &conf); err != nil {
+				errs <- err
+				return
+			}
+
+			if conf.Name != vals["name"] || conf.MaxConns != i {
+				errs <- fmt.Errorf("expected tenant%d, found %+v", i, conf)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	var conf tenantConfig
+	if err := b.Bind(config.Values{"name": "x"}, &conf); err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	var other struct{ Name string }
+	if err := b.Bind(config.Values{"name": "x"}, &other); err == nil {
+		t.Fatal("expected error for the wrong type, found no error")
+	}
+}
+
+func TestBindAll(t *testing.T) {
+	vals := config.Values{
+		"name":       "app",
+		"db.host":    "localhost",
+		"db.port":    "5432",
+		"cache.size": "x",
+		"extra":      "1",
+	}
+
+	var app struct {
+		Name string `config:"name"`
+	}
+	var db struct {
+		Host string `config:"host"`
+		Port int    `config:"port"`
+	}
+	var cache struct {
+		Size int `config:"size"`
+	}
+	err := config.BindAll(vals, &app, config.Section{Prefix: "db", Obj: &db},
+		config.Section{Prefix: "cache", Obj: &cache})
+
+	if app.Name != "app" || db.Host != "localhost" || db.Port != 5432 {
+		t.Fatalf("expected app and db to be read, found %+v and %+v", app, db)
+	}
+
+	errs := config.Errors(err)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, found %v", err)
+	}
+
+	if errs[0].Key != "cache.size" || errs[0].Kind != config.KindInvalid {
+		t.Fatalf("expected cache.size to be invalid, found %v", errs[0])
+	}
+
+	if errs[1].Key != "extra" || errs[1].Kind != config.KindUnknown {
+		t.Fatalf("expected extra to be unknown, found %v", errs[1])
+	}
+}
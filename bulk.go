@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BulkResult is the result of reading one of the files given to
+// [config.ReadBulk].
+type BulkResult[T any] struct {
+	Path   string
+	Config T
+	// Err is the error reading the file, if any.
+	Err error
+}
+
+// BulkError summarizes the files which couldn't be read by
+// [config.ReadBulk], since listing every error for thousands of files isn't
+// readable. The errors for each file are in its BulkResult.
+type BulkError struct {
+	// Failed is the number of files which couldn't be read, out of Total.
+	Failed int
+	Total  int
+	// Kinds holds the number of errors of each kind.
+	Kinds map[ErrorKind]int
+}
+
+func (e *BulkError) Error() string {
+	kinds := make([]string, 0, len(e.Kinds))
+	for kind, n := range e.Kinds {
+		kinds = append(kinds, fmt.Sprintf("%v %v", n, kind))
+	}
+	sort.Strings(kinds)
+	return fmt.Sprintf("%v of %v configs failed to read (%v)", e.Failed, e.Total, strings.Join(kinds, ", "))
+}
+
+// ReadBulk reads each of the files at paths into its own T, which must be a
+// struct, as [config.ReadFile] does, for programs which load many configs of
+// the same kind, such as one per tenant. The files are read concurrently by up
+// to [config.WithConcurrency] workers, and the fields of T are only looked up
+// once. The results are in the order of paths.
+//
+// If any file can't be read, the error returned is a *BulkError summarizing
+// the failures. [config.WithReport], [config.WithWasSet] and
+// [config.WithAuditFile] are ignored, since each describes a single read, and
+// every file would write to the same one at once.
+func ReadBulk[T any](paths []string, opts ...Option) ([]BulkResult[T], error) {
+	if reflect.TypeFor[T]().Kind() != reflect.Struct {
+		return nil, ErrInvalid
+	}
+
+	o := newOptions(opts)
+	o.report = nil
+	o.wasSet = nil
+	o.auditPath = ""
+	o.effective = nil
+
+	results := make([]BulkResult[T], len(paths))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range min(o.concurrency, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				path := paths[i]
+				results[i].Path = path
+				results[i].Err = o.finish(read(path, func() ([]parsedFile, error) {
+					f, err := parseFile(path, o)
+					return []parsedFile{f}, err
+				}, &results[i].Config, o))
+			}
+		}()
+	}
+
+	for i := range paths {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	bulk := &BulkError{Total: len(paths), Kinds: map[ErrorKind]int{}}
+	for _, r := range results {
+		if r.Err == nil {
+			continue
+		}
+
+		bulk.Failed += 1
+		for _, e := range Errors(r.Err) {
+			bulk.Kinds[e.Kind] += 1
+		}
+	}
+	if bulk.Failed > 0 {
+		return results, bulk
+	}
+	return results, nil
+}
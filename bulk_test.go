@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestReadBulk(t *testing.T) {
+	type tenant struct {
+		Name  string
+		Quota int `config:"quota,optional"`
+	}
+
+	contents := make([]string, 100)
+	for i := range contents {
+		contents[i] = fmt.Sprintf("name = tenant%d\nquota = %d", i, i)
+	}
+	contents[10] = "quota = 1"
+	contents[20] = "name = x\nquota = lots"
+	contents[30] = "= 1"
+	paths := writeFiles(t, contents...)
+
+	results, err := config.ReadBulk[tenant](paths, config.WithConcurrency(8),
+		config.WithEnvironment(config.EnvMap{}))
+	var bulk *config.BulkError
+	if !errors.As(err, &bulk) {
+		t.Fatalf("expected a BulkError, found %v", err)
+	}
+
+	expected := "3 of 100 configs failed to read (1 invalid, 1 missing, 1 syntax)"
+	if err.Error() != expected {
+		t.Fatalf(`expected "%v", found "%v"`, expected, err)
+	}
+
+	for i, r := range results {
+		if r.Path != paths[i] {
+			t.Fatalf("expected %v, found %v", paths[i], r.Path)
+		}
+
+		switch i {
+		case 10, 20, 30:
+			if r.Err == nil {
+				t.Fatalf("expected an error for %v, found none", r.Path)
+			}
+		default:
+			if r.Err != nil || r.Config.Name != fmt.Sprintf("tenant%d", i) || r.Config.Quota != i {
+				t.Fatalf("expected tenant%d, found %+v: %v", i, r.Config, r.Err)
+			}
+		}
+	}
+
+	if _, err := config.ReadBulk[int](paths); err != config.ErrInvalid {
+		t.Fatalf("expected ErrInvalid, found %v", err)
+	}
+}
+
+func TestReadBulkSingleReadOptions(t *testing.T) {
+	type tenant struct {
+		Name string
+	}
+	var set struct {
+		Name bool
+	}
+	audit := filepath.Join(t.TempDir(), "effective.conf")
+	paths := writeFiles(t, "name = a", "name = b", "name = c")
+
+	_, err := config.ReadBulk[tenant](paths, config.WithConcurrency(3), config.WithWasSet(&set),
+		config.WithAuditFile(audit), config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to read configs: %v", err)
+	}
+
+	if set.Name {
+		t.Fatal("expected WithWasSet to be ignored")
+	}
+
+	if _, err := os.Stat(audit); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected WithAuditFile to be ignored, found %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// Completion describes an option for shell completion, as returned by
+// [config.Completions].
+type Completion struct {
+	Key string
+	// Type is the Go type of the option.
+	Type reflect.Type
+	// Values holds the values the option can take, if they're a fixed set:
+	// the names registered with [config.RegisterEnum], sorted, or true and
+	// false for booleans.
+	Values []string
+	// Description is the text of the field's `comment:""` struct tag.
+	Description string
+}
+
+// Completions returns the options of the struct obj points to for completing
+// them in a shell, such as for a `--set key=value` flag or a tool which
+// checks config files, in the order of the struct's fields.
+func Completions(obj any) ([]Completion, error) {
+	v, err := structValue(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := typeFields(v.Type())
+	comps := make([]Completion, len(plan))
+	for i, fi := range plan {
+		comps[i] = Completion{
+			Key:         fi.name,
+			Type:        fi.f.Type,
+			Values:      completionValues(fi.f.Type),
+			Description: fi.f.Tag.Get("comment"),
+		}
+	}
+	return comps, nil
+}
+
+// completionValues returns the values a field of type t can take, if they're
+// a fixed set.
+func completionValues(t reflect.Type) []string {
+	if names, ok := lookupEnum(t); ok {
+		values := make([]string, 0, len(names))
+		for name := range names {
+			values = append(values, name)
+		}
+		sort.Strings(values)
+		return values
+	}
+
+	if t.Kind() == reflect.Bool && !hasParser(t) && !hasConversion(t) {
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
+// WriteCompletions writes comps to w one candidate per line, as `key=` for an
+// option which can take any value, or `key=value` for each of the values of
+// one which takes a fixed set. Each candidate is followed by a tab and the
+// option's type and description, as fish's `complete` expects; other shells
+// can cut the line at the tab, as with `cut -f1`.
+func WriteCompletions(w io.Writer, comps []Completion) error {
+	b := bufio.NewWriter(w)
+	for _, c := range comps {
+		desc := c.Type.String()
+		if c.Description != "" {
+			desc += ": " + c.Description
+		}
+
+		if len(c.Values) == 0 {
+			fmt.Fprintf(b, "%v=\t%v\n", c.Key, desc)
+			continue
+		}
+		for _, val := range c.Values {
+			fmt.Fprintf(b, "%v=%v\t%v\n", c.Key, val, desc)
+		}
+	}
+	return b.Flush()
+}
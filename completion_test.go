@@ -0,0 +1,38 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+type completionMode int
+
+func TestCompletions(t *testing.T) {
+	config.RegisterEnum(map[string]completionMode{"fast": 0, "safe": 1})
+
+	var conf struct {
+		Mode    completionMode `comment:"How to run"`
+		Verbose bool
+		Port    int `config:"server.port"`
+	}
+	comps, err := config.Completions(&conf)
+	if err != nil {
+		t.Fatalf("failed to list completions: %v", err)
+	}
+
+	var b strings.Builder
+	if err := config.WriteCompletions(&b, comps); err != nil {
+		t.Fatalf("failed to write completions: %v", err)
+	}
+
+	expected := "mode=fast\tconfig_test.completionMode: How to run\n" +
+		"mode=safe\tconfig_test.completionMode: How to run\n" +
+		"verbose=true\tbool\n" +
+		"verbose=false\tbool\n" +
+		"server.port=\tint\n"
+	if b.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, b.String())
+	}
+}
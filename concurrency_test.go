@@ -0,0 +1,97 @@
+package config_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+// These tests are most useful with -race.
+
+func TestConcurrentReads(t *testing.T) {
+	type server struct {
+		Host  string
+		Port  int
+		Debug bool `config:"debug,optional"`
+	}
+
+	vals := config.Values{"a.host": "localhost", "a.port": "80", "b.host": "example.com", "b.port": "8080"}
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 16; i += 1 {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			var conf server
+			if err := config.ReadSection(vals, "a", &conf,
+				config.WithEnvironment(config.EnvMap{})); err != nil {
+				errs <- err
+			} else if conf.Port != 80 {
+				errs <- fmt.Errorf("expected 80, found %v", conf.Port)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var conf server
+			if err := config.ReadSection(vals, "b", &conf,
+				config.WithEnvironment(config.EnvMap{})); err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var conf server
+			var report config.Report
+			err := config.Read("<input>", strings.NewReader("host = x\nport = 1"), &conf, config.WithReport(&report),
+				config.WithEnvironment(config.EnvMap{}))
+			if err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := config.Fields(&struct{ server }{}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("failed to read concurrently: %v", err)
+	}
+}
+
+func TestReadValuesCopied(t *testing.T) {
+	var conf struct {
+		Port config.Lazy[int]
+	}
+	var report config.Report
+	vals, err := config.ReadValues("<input>", strings.NewReader("port = 8080\nother = 1"), &conf, config.WithReport(&report),
+		config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		delete(vals, "port")
+		vals["other"] = "2"
+	}()
+
+	port, err := conf.Port.Get()
+	wg.Wait()
+	if err != nil || port != 8080 {
+		t.Fatalf("expected 8080 without error, found %v: %v", port, err)
+	}
+
+	if report.Values["other"] != "1" || report.Values["port"] != "8080" {
+		t.Fatalf("expected the report's values to be unchanged, found %v", report.Values)
+	}
+}
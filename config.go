@@ -9,30 +9,141 @@
 // All the `struct`'s members will be parsed from the config file and from the
 // environment variables. Specifically, environment variables override config
 // options, and are all uppercase. For example, a config option called `port`
-// would be overriden by the `PORT` environment variable.
+// would be overriden by the `PORT` environment variable. This order can be
+// changed, or either source left out, with [config.WithPrecedence]. Adding
+// `frozen` to the config struct tag means the option can only be set in the
+// file, and the environment variable is ignored.
+//
+// Characters which can't appear in an environment variable's name, such as
+// `.`, are replaced with `_`, and a common prefix can be added with
+// [config.WithEnvPrefix]. Without a prefix, variables which are commonly set
+// for other reasons, such as `HOST`, `PORT` or `USER`, override options of
+// the same name, so programs should usually give one. Tests can read from a
+// fixed set of variables instead of the process's with
+// [config.WithEnvironment]. [config.ExportEnv] gives the variables for a
+// struct, which is useful for passing the configuration to child processes,
+// and [config.SetCommandEnv] adds them to an `exec.Cmd`. Options holding
+// credentials should have `secret` added to the config struct tag, which
+// keeps them from being passed on to child processes this way.
 //
 // By default, all struct members are converted to snake_case when added to the
 // config file, but this can be overriden using the `config:""` struct tag.
-// Note that the name cannot contain commas, and cannot be the word `optional`.
+// Note that the name cannot contain commas, and cannot be the word `optional`,
+// `frozen`, `secret`, `restart` or `raw`. A field tagged `config:"-"` isn't
+// an option at all, and is left alone. `restart` marks an option which only
+// takes effect when the program starts, for [config.Classify]. `raw` marks a
+// string option whose value is set exactly as it's given, without
+// expressions or any [config.ValueParser] or registered conversion of its
+// type, for values like templates meant for other programs. A raw value is
+// still parsed from the file as usual, so a `#` in it must be quoted.
 //
 // To make something optional in the config, add `optional` to the config
 // struct tag. So by itself it would be `config:"optional"`, and with the name
 // `shredder` it would be `config:"shredder,optional"` or
 // `config:"optional,shredder"` (although the first is preferred).
 //
+// A field can also be required only when another option has a particular
+// value, using the `requiredif:""` struct tag. For example
+// `requiredif:"tls_enabled=true"` makes the field required only if the
+// `tls_enabled` option is set to `true` (or any other spelling of true a bool
+// option accepts, such as `TRUE` or `1`), and `requiredif:"tls_cert"` makes it
+// required whenever `tls_cert` is present at all. Other values are compared as
+// exact text. The other option is
+// referred to by its name in the config file, not the Go field name, and is
+// relative to the struct holding the field, so `requiredif:"tls_cert"` on a
+// field of a struct nested as `server` refers to `server.tls_cert`.
+//
+// Values can be checked before they are converted using the `validate:""`
+// struct tag, which takes a comma separated list of validators. The built-in
+// validators are `url`, `hostport`, `email`, `cidr` and `file` (the file must
+// exist and not be a directory). More can be added with
+// [config.RegisterValidator]. Validators aren't run on an optional option set
+// to an empty value.
+//
+// Options being phased out can be marked with the `deprecated:""` struct tag,
+// as described by [config.Deprecation], so that setting them is reported by
+// [config.OnDeprecated] and becomes an error once the version given to
+// [config.WithVersion] reaches the one they're removed in.
+//
+// Numbers written for a locale can be read with the `number:""` struct tag on
+// an integer or float field, or a [config.Lazy] of one. `number:"comma"` reads
+// a comma as the decimal separator, so `1,5` is 1.5, and `number:"point"` a
+// point. Either way the digits of the whole part may be grouped in threes by
+// the other separator, a space or an apostrophe, as in `1.234,5` or
+// `1,234.5`. The tag only applies to values in files, and [config.Write]
+// writes floats with the tag's separator: environment variables and overrides
+// are given as `1.5`, as for other fields.
+//
+// Constraints spanning several options can be declared with the `group:""`
+// struct tag, which takes a group name and a rule: `group:"listener,exactlyone"`.
+// The rule can be `exactlyone`, `atmostone` (the options are mutually
+// exclusive) or `atleastone`, and only needs to be given on one member of the
+// group. Options belonging to a group are always optional on their own. Like
+// the options named by `requiredif`, a group belongs to the struct holding its
+// fields, so two fields of the same nested struct type have separate groups.
+//
+// Fields can be strings, integers, floats, complex numbers (in the form
+// accepted by `strconv.ParseComplex`, like `1+2i`) and booleans, as well as
+// `*regexp.Regexp`, [config.Glob], `*time.Location` (loaded with
+// `time.LoadLocation`), email addresses as `mail.Address` or a comma separated
+// `[]mail.Address`, and `big.Int` and `big.Float` (parsed with enough
+// precision to hold every digit given). Any other type must implement
+// [config.ValueParser], [config.ContextValueParser] or
+// `encoding.TextUnmarshaler`, or have a conversion registered with
+// [config.RegisterType]. The parser interfaces are used in preference to the
+// built-in conversions for any type that implements them.
+//
+// The fields of an embedded struct are read as if they were fields of the
+// struct embedding it, which allows a common block of options to be reused.
+// The fields of any other struct field which isn't a value itself are read
+// from keys made of the name of the struct field, a dot and the name of the
+// nested option, so a field `Redis` of a struct with a field `Host` is read
+// from `redis.host`. The options of a nested struct inherit optional, frozen
+// and secret from the struct field. A pointer to a nested struct is only
+// allocated if any of its options are set, and is left nil otherwise, which
+// suits optional blocks of options; once allocated, its required options must
+// all be present.
+//
+// The `config:",prefix=name"` struct tag on a nested struct field reads its
+// options from keys starting with `name.` instead, whatever the field's name.
+// An empty prefix, as in `config:",prefix="`, reads them without any prefix,
+// as if the struct were embedded.
+//
+// Types whose values are a fixed set of named constants can be registered
+// with [config.RegisterEnum], after which fields of that type are set by name.
+//
+// A struct can be written back out in the same format with [config.Write].
+// Types which implement [config.ValueParser] should implement
+// [config.ValueWriter] (or `encoding.TextMarshaler`) so they can be written.
+//
+// Every function in the package is safe to call from several goroutines at
+// once, including on the same input, such as Values shared between calls to
+// [config.ReadSection], and with the same struct type. The registries, like
+// [config.RegisterType], and the cache of struct fields are synchronized
+// internally. A struct must not be used while it is being read into, since
+// its fields are set without synchronization.
+//
 // The `#` character is used as a comment character. Everything after one of
 // these is ignored. If you need a value to contain a `#`, you can enclose it
-// in single quotes `'` or double quotes `"`.
+// in single quotes `'` or double quotes `"`. Whitespace at the start and end of
+// a value is ignored too, unless the value is quoted, so `sep = " | "` keeps
+// the spaces around the `|`.
 package config
 
 import (
 	"bufio"
+	"encoding"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
+	"path"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -42,8 +153,27 @@ type ValueParser interface {
 	ParseConfigValue(string) error
 }
 
+// FieldSetter is the interface implemented by structs which set some of their
+// unexported fields themselves, so they can keep them encapsulated. An
+// unexported field with a `config:""` struct tag in a struct whose pointer
+// implements FieldSetter is read like any other option, except that instead
+// of being converted and set, its value is passed to SetConfigField along with
+// the option's name, without the prefix of any struct it is nested in.
+// Unexported fields without the tag are always ignored.
+type FieldSetter interface {
+	SetConfigField(name, value string) error
+}
+
+// ContextValueParser is the interface implemented by types that need to see
+// the rest of the configuration to parse themselves, for example to resolve a
+// reference to something defined elsewhere in the file. key is the name of
+// the option being parsed, and all holds every option in the configuration.
+type ContextValueParser interface {
+	ParseConfigValue(key string, all Values) error
+}
+
 var (
-	ErrInvalid = errors.New("config.Read was not passed a pointer to a struct")
+	ErrInvalid = errors.New("config was not passed a pointer to a struct")
 	ErrSyntax  = errors.New("syntax error")
 )
 
@@ -54,7 +184,11 @@ type lexer struct {
 	// the character used to start the string, either ' or "
 	stringChar rune
 	skipLine   bool
-	err        error
+	// bareKeys means a key without a value is set to true.
+	bareKeys bool
+	// comment holds the text of the comment on the line, if any.
+	comment strings.Builder
+	err     error
 }
 
 // Skips whitespace, returning any read errors encountered while doing so.
@@ -72,6 +206,17 @@ func (l *lexer) skipWhitespace() error {
 	}
 }
 
+// readComment reads the rest of the line as a comment.
+func (l *lexer) readComment() {
+	for {
+		c, _, err := l.reader.ReadRune()
+		if err != nil {
+			return
+		}
+		l.comment.WriteRune(c)
+	}
+}
+
 func (l *lexer) unexpected(err error) stateFn {
 	l.err = fmt.Errorf("an unexpected error occurred: %w", err)
 	return nil
@@ -82,49 +227,260 @@ func (l *lexer) error(err error) stateFn {
 	return nil
 }
 
+// bareKey handles a key with no `=` after it.
+func (l *lexer) bareKey() stateFn {
+	if !l.bareKeys {
+		return l.error(errors.New("unexpected identifier"))
+	}
+
+	l.right.WriteString("true")
+	return nil
+}
+
 type stateFn func(l *lexer) stateFn
 
+// Values holds the key-value pairs given in a configuration file.
+type Values map[string]string
+
 // Parse parses a configuration file from the given reader into a `map`
 // containing each key-value pair given in the file.
-func Parse(path string, r io.Reader) (map[string]string, error) {
-	result := map[string]string{}
+func Parse(path string, r io.Reader, opts ...Option) (Values, error) {
+	o := newOptions(opts)
+	vals, err := parse(path, r, o)
+	return vals, o.finish(err)
+}
+
+func parse(path string, r io.Reader, o *options) (Values, error) {
+	f, err := parseNulls(path, r, o)
+	return f.vals, err
+}
+
+// Entry is a single assignment in a config file, as returned by
+// [config.ParseEntries].
+type Entry struct {
+	Key   string
+	Value string
+	// Line is the line the entry starts on.
+	Line int
+	// Null means the value was null, with [config.AllowNull].
+	Null bool
+	// Directives holds the names given in `# config:name,...` comments on
+	// the lines just above the entry or at the end of its line, such as
+	// `deprecated` or `secret`, for tools which annotate files.
+	Directives []string
+	// Comments holds the text of the comments on the lines just above the
+	// entry and at the end of its line, without the `#`, for tools which
+	// rewrite files.
+	Comments []string
+}
+
+// ParseEntries parses a configuration file like [config.Parse], but returns
+// each assignment in the order it appears in the file, with its line number
+// and directives. Keys set more than once appear more than once.
+func ParseEntries(path string, r io.Reader, opts ...Option) ([]Entry, error) {
+	o := newOptions(opts)
+	r, err := input(path, r, o)
+	if err != nil {
+		return nil, o.finish(err)
+	}
+
+	entries, err := parseEntries(path, r, o)
+	return entries, o.finish(err)
+}
+
+// parseNulls parses the file at path from r, keeping the keys set to null
+// with [config.AllowNull] apart from the rest.
+func parseNulls(path string, r io.Reader, o *options) (parsedFile, error) {
+	r, err := input(path, r, o)
+	if err != nil {
+		return parsedFile{}, err
+	}
+
+	entries, err := parseEntries(path, r, o)
+	if err != nil {
+		return parsedFile{}, err
+	}
+	return newParsedFile(path, entries), nil
+}
+
+// newParsedFile returns the options set by entries, parsed from the file at
+// path.
+func newParsedFile(path string, entries []Entry) parsedFile {
+	f := parsedFile{
+		path:    path,
+		vals:    Values{},
+		nulls:   map[string]bool{},
+		entries: entries,
+	}
+	for _, e := range entries {
+		if e.Null {
+			delete(f.vals, e.Key)
+			f.nulls[e.Key] = true
+			continue
+		}
+
+		f.vals[e.Key] = e.Value
+		delete(f.nulls, e.Key)
+	}
+	return f
+}
+
+func parseEntries(path string, r io.Reader, o *options) ([]Entry, error) {
+	if o.properties {
+		return parseProperties(path, r, o)
+	}
+
+	var entries []Entry
+	// pending holds the directives given above the next entry, and
+	// comments the comments.
+	var pending, comments []string
 	s := bufio.NewScanner(r)
 	lineNo := 1
 	for ; s.Scan(); lineNo += 1 {
 		text := strings.TrimSpace(s.Text())
 		if text == "" {
+			pending, comments = nil, nil
 			continue
 		}
 
+		if o.allowExport {
+			text = stripExport(text)
+		}
+
 		l := lexer{
-			reader: strings.NewReader(text),
+			reader:   strings.NewReader(text),
+			bareKeys: o.bareKeys,
 		}
 
 		for state := beforeEquals; state != nil; {
 			state = state(&l)
 			if l.err != nil {
-				return nil, fmt.Errorf("error:%v:%v: %w", path, lineNo, l.err)
+				return nil, &Error{
+					File: path,
+					Line: lineNo,
+					Kind: KindSyntax,
+					Err:  l.err,
+				}
 			}
 		}
 
 		if l.skipLine {
+			pending = append(pending, directives(l.comment.String())...)
+			comments = append(comments, strings.TrimSpace(l.comment.String()))
 			continue
 		}
 
 		left := strings.TrimSpace(l.left.String())
-		right := strings.TrimSpace(l.right.String())
+		right := l.right.String()
+		if l.stringChar == 0 {
+			// Only quoted values keep their leading and trailing
+			// whitespace.
+			right = strings.TrimSpace(right)
+		}
 
 		// An empty left side is not allowed.
 		if left == "" {
-			return nil, fmt.Errorf(
-				"error:%v:%v: left side of assignment empty",
-				path, lineNo,
-			)
+			return nil, &Error{
+				File: path,
+				Line: lineNo,
+				Kind: KindSyntax,
+				Err:  errors.New("left side of assignment empty"),
+			}
+		}
+
+		if o.keyPattern != nil && !o.keyPattern.MatchString(left) {
+			return nil, &Error{
+				File: path,
+				Line: lineNo,
+				Key:  left,
+				Kind: KindSyntax,
+				Err:  fmt.Errorf("invalid key '%v'", left),
+			}
+		}
+
+		e := Entry{
+			Key:        left,
+			Value:      right,
+			Line:       lineNo,
+			Null:       o.allowNull && l.stringChar == 0 && (right == "null" || right == "~"),
+			Directives: append(pending, directives(l.comment.String())...),
+			Comments:   comments,
+		}
+		if l.comment.Len() > 0 {
+			e.Comments = append(e.Comments, strings.TrimSpace(l.comment.String()))
 		}
-		result[left] = right
+		pending, comments = nil, nil
+
+		if from, ok := strings.CutPrefix(right, "*"); ok && o.references && l.stringChar == 0 {
+			block := copyBlock(entries, e, from)
+			if len(block) == 0 {
+				return nil, &Error{
+					File: path,
+					Line: lineNo,
+					Key:  left,
+					Kind: KindSyntax,
+					Err:  fmt.Errorf("no options under '%v' to copy", from),
+				}
+			}
+			entries = append(entries, block...)
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, &Error{File: path, Kind: KindIO, Err: err}
 	}
 
-	return result, nil
+	return entries, nil
+}
+
+// copyBlock returns a copy of each of entries whose key is nested in the
+// option from, nested in the key of e instead, for a reference made by e with
+// [config.AllowReferences].
+func copyBlock(entries []Entry, e Entry, from string) []Entry {
+	var block []Entry
+	for _, entry := range entries {
+		if rest, ok := strings.CutPrefix(entry.Key, from+"."); ok {
+			entry.Key = joinName(e.Key, rest)
+			entry.Line = e.Line
+			entry.Directives = e.Directives
+			entry.Comments = nil
+			block = append(block, entry)
+		}
+	}
+	return block
+}
+
+// directives returns the names in comment, if it is a `config:` directive.
+func directives(comment string) []string {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(comment), "config:")
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, name := range strings.Split(rest, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// stripExport removes the shell keyword `export` from the start of line,
+// unless it is the key being set.
+func stripExport(line string) string {
+	rest, ok := strings.CutPrefix(line, "export")
+	if !ok {
+		return line
+	}
+
+	trimmed := strings.TrimLeftFunc(rest, unicode.IsSpace)
+	if trimmed == rest || trimmed == "" || trimmed[0] == '=' {
+		return line
+	}
+	return trimmed
 }
 
 // The left hand side of the assignment.
@@ -134,7 +490,7 @@ func beforeEquals(l *lexer) stateFn {
 		if err != nil {
 			if err == io.EOF {
 				if l.left.Len() > 0 {
-					return l.error(errors.New("unexpected identifier"))
+					return l.bareKey()
 				}
 				return nil
 			}
@@ -164,8 +520,9 @@ func beforeEquals(l *lexer) stateFn {
 				return afterEquals
 			}
 		case '#':
+			l.readComment()
 			if l.left.Len() > 0 {
-				return l.error(errors.New("unexpected identifier"))
+				return l.bareKey()
 			}
 
 			l.skipLine = true
@@ -192,6 +549,7 @@ func afterEquals(l *lexer) stateFn {
 		}
 
 		if c == '#' {
+			l.readComment()
 			return nil
 		}
 
@@ -225,7 +583,10 @@ func afterEqualsString(l *lexer) stateFn {
 
 	l.skipWhitespace()
 	ch, _, err := l.reader.ReadRune()
-	if err == io.EOF || ch == '#' {
+	if err == io.EOF {
+		return nil
+	} else if ch == '#' {
+		l.readComment()
 		return nil
 	}
 
@@ -240,152 +601,643 @@ func afterEqualsString(l *lexer) stateFn {
 }
 
 const (
-	noField            = "error parsing config '%v': required value %v not present"
-	errorParsingConfig = "error parsing config '%v': %w"
-	overflow           = "value '%v' would overflow type"
-	unsupported        = "attempted to parse unsupported type '%v' (hint: it doesn't implement config.ValueParser)"
+	noField     = "required value %v not present"
+	noFieldIf   = "required value %v not present (required when %v)"
+	unknownKey  = "unknown option %v"
+	overflow    = "value '%v' would overflow type"
+	unsupported = "attempted to parse unsupported type '%v' (hint: it doesn't implement config.ValueParser or encoding.TextUnmarshaler)"
 )
 
-// Read parses a configuration file at the given path into a struct.
-func Read(path string, r io.Reader, obj any) error {
-	vals, err := Parse(path, r)
+// Read parses a configuration file at the given path into a struct. If the
+// file layer has been left out with [config.WithPrecedence], r isn't read and
+// may be nil.
+func Read(path string, r io.Reader, obj any, opts ...Option) error {
+	o := newOptions(opts)
+	return o.finish(read(path, func() ([]parsedFile, error) {
+		f, err := parseNulls(path, r, o)
+		return []parsedFile{f}, err
+	}, obj, o))
+}
+
+// ReadValues reads a configuration like [config.Read], and also returns the
+// effective value of every option after the environment and overrides have
+// been applied, including keys in the file which the struct doesn't have.
+// This lets unbound keys be handed to other code without parsing the file
+// again. The values are returned even if reading fails.
+func ReadValues(path string, r io.Reader, obj any, opts ...Option) (Values, error) {
+	o := newOptions(opts)
+	var vals Values
+	o.effective = &vals
+	err := read(path, func() ([]parsedFile, error) {
+		f, err := parseNulls(path, r, o)
+		return []parsedFile{f}, err
+	}, obj, o)
+	return vals, o.finish(err)
+}
+
+// ReadEnv reads a struct purely from environment variables, without a config
+// file. Required and optional options work just as they do for [config.Read].
+// Errors refer to the file `<environment>`.
+func ReadEnv(obj any, opts ...Option) error {
+	opts = append(opts, WithPrecedence(LayerEnv))
+	return Read("<environment>", nil, obj, opts...)
+}
+
+// ReadSection reads a struct from the options in vals whose keys start with
+// prefix and a dot, as if it were a nested struct with that prefix. This lets
+// independent packages each read their own struct from a shared file, parsed
+// once with [config.Parse]. Environment variables are named after the whole
+// key, so `port` in the section `metrics` is set by `METRICS_PORT`. Errors
+// refer to the file `<values>` and to the whole key.
+func ReadSection(vals Values, prefix string, obj any, opts ...Option) error {
+	o := newOptions(opts)
+	o.section = prefix
+	return o.finish(read("<values>", func() ([]parsedFile, error) {
+		return []parsedFile{{path: "<values>", vals: vals}}, nil
+	}, obj, o))
+}
+
+// Validate checks a configuration as [config.Read] would read it into the
+// struct prototype points to, without changing prototype, and returns the
+// [Report] of the read along with any errors. The whole pipeline runs, from
+// parsing to the environment, overrides and every constraint, so a control
+// plane can check a config submitted by a user using only the struct type,
+// without creating the objects of the program the config is for. The values
+// in prototype are the defaults, as they would be for Read. The report is
+// returned even if the config isn't valid.
+func Validate(path string, r io.Reader, prototype any, opts ...Option) (*Report, error) {
+	v, err := structValue(prototype)
+	if err != nil {
+		return nil, err
+	}
+
+	scratch := reflect.New(v.Type())
+	scratch.Elem().Set(v)
+	copyPointers(scratch.Elem(), typeFields(v.Type()))
+
+	var report Report
+	opts = append(opts[:len(opts):len(opts)], WithReport(&report))
+	err = Read(path, r, scratch.Interface(), opts...)
+	return &report, err
+}
+
+// copyPointers replaces each of the pointers in v, which is a shallow copy of
+// another struct, with a pointer to a copy, so that setting the options of v
+// doesn't change the original. This covers both pointers to nested structs
+// and options held through a pointer, which a ValueParser parses in place.
+func copyPointers(v reflect.Value, plan []fieldInfo) {
+	copied := map[string]bool{}
+	for _, fi := range plan {
+		// The blocks come before the option in index, so each is copied
+		// before the pointers inside it. Unexported fields can't be set,
+		// and are only changed through their FieldSetter anyway.
+		ends := fi.blocks
+		if !fi.setter {
+			ends = append(ends[:len(ends):len(ends)], len(fi.index))
+		}
+		for _, n := range ends {
+			key := fmt.Sprint(fi.index[:n])
+			if copied[key] {
+				continue
+			}
+			copied[key] = true
+
+			field, err := v.FieldByIndexErr(fi.index[:n])
+			if err != nil || field.Kind() != reflect.Pointer || field.IsNil() {
+				break
+			}
+
+			p := reflect.New(field.Type().Elem())
+			p.Elem().Set(field.Elem())
+			field.Set(p)
+		}
+	}
+}
+
+// read reads the struct obj points to from the layers given in o. The files
+// for the file layer come from calling files, and are merged in order. Errors
+// which don't come from a particular file refer to path.
+func read(path string, files func() ([]parsedFile, error), obj any, o *options) error {
+	v, err := structValue(obj)
 	if err != nil {
 		return err
 	}
 
-	v := reflect.ValueOf(obj)
-	if v.Kind() != reflect.Pointer || v.IsNil() {
-		return ErrInvalid
+	s := readState{
+		path:             path,
+		vals:             Values{},
+		sources:          map[string]Source{},
+		cleared:          map[string]bool{},
+		groups:           groupSet{},
+		envPrefix:        o.envPrefix,
+		env:              o.env,
+		fsys:             o.fsys,
+		version:          o.version,
+		onDeprecated:     o.onDeprecated,
+		allowUnsupported: o.skipUnsupported,
+	}
+	fields := sectionFields(v.Type(), o.section)
+	if o.report != nil {
+		s.timings = map[string]time.Duration{}
+		defer s.fillReport(o.report, v, fields)
+	}
+	if o.effective != nil {
+		defer func() { *o.effective = maps.Clone(s.vals) }()
 	}
-	v = reflect.Indirect(v)
-	if v.Kind() != reflect.Struct {
-		return ErrInvalid
+	if o.wasSet != nil {
+		defer s.fillWasSet(o.wasSet, o.section, fields)
 	}
 
-	numFields := v.NumField()
-	for i := 0; i < numFields; i += 1 {
-		field := v.Field(i)
-		if !field.IsValid() || !field.CanSet() {
-			continue
-		}
+	for _, layer := range o.layers {
+		switch layer {
+		case LayerFile:
+			start := time.Now()
+			parsed, err := files()
+			s.parseTime = time.Since(start)
+			if err != nil {
+				return err
+			}
 
-		f := v.Type().Field(i)
-		// convert name to snake_case
-		name := toSnakeCase(f.Name)
-		typ := f.Type
-		kind := typ.Kind()
-		optional := false
-		if tag := f.Tag.Get("config"); tag != "" {
-			for _, x := range strings.Split(tag, ",") {
-				switch x {
-				case "optional":
-					optional = true
-				default:
-					name = x
+			if err := checkFinal(parsed, o); err != nil {
+				return err
+			}
+
+			for _, f := range parsed {
+				if f.vals == nil {
+					// The file is missing, which AllowMissingFile
+					// allows.
+					continue
+				}
+
+				vals := f.vals
+				if o.migrations != nil {
+					if vals, err = migrate(f, o); err != nil {
+						return err
+					}
+				}
+
+				s.files = append(s.files, f.path)
+				for key, val := range vals {
+					s.set(key, val, Source{LayerFile, f.path})
+				}
+				for key := range f.nulls {
+					s.clear(key)
 				}
 			}
+		case LayerEnv:
+			s.applyEnv(fields)
+		}
+	}
+	s.applyOverrides(fields, o.overrides)
+
+	if o.expressions {
+		if err := s.evaluate(fields); err != nil {
+			return err
+		}
+	}
+
+	if err := s.checkPolicies(o.policies); err != nil {
+		return err
+	}
+
+	if o.disallowUnknown {
+		if err := s.checkUnknown(fields, o); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	err = s.bind(v, fields)
+	s.bindTime = time.Since(start)
+	if err == nil && o.auditPath != "" {
+		err = s.writeAudit(o.auditPath, fields)
+	}
+	return err
+}
+
+// readState holds the state needed while reading into a struct.
+type readState struct {
+	path string
+	// vals holds the effective value of each option, after environment
+	// variables have been applied.
+	vals Values
+	// sources holds where the value of each option in vals came from.
+	sources map[string]Source
+	// cleared holds the options set to null, which aren't in vals.
+	cleared map[string]bool
+	// files holds the config files read, in the order they were merged.
+	files []string
+	// timings holds how long converting each option took, and the time
+	// spent in each stage, if they're being reported.
+	timings   map[string]time.Duration
+	parseTime time.Duration
+	bindTime  time.Duration
+	groups    groupSet
+	envPrefix string
+	env       Environment
+	// fsys holds the files checked by the `file` validator, if set with
+	// WithFS.
+	fsys fs.FS
+	// version is the version given to WithVersion, and onDeprecated the
+	// function given to OnDeprecated.
+	version      string
+	onDeprecated func(Deprecation)
+	// deprecations holds the deprecated options set.
+	deprecations []Deprecation
+	// unsupported holds the options whose fields have types which can't be
+	// parsed, if allowUnsupported excuses them.
+	allowUnsupported bool
+	unsupported      []*Error
+}
+
+// set sets the value of the option key, which came from source.
+func (s *readState) set(key, val string, source Source) {
+	s.vals[key] = val
+	s.sources[key] = source
+	delete(s.cleared, key)
+}
+
+// clear unsets the option key, so it takes its zero value and counts as
+// missing.
+func (s *readState) clear(key string) {
+	delete(s.vals, key)
+	delete(s.sources, key)
+	s.cleared[key] = true
+}
+
+// applyEnv overrides the values from the file with those from environment
+// variables for each of fields. Frozen fields can only be set from the file.
+func (s *readState) applyEnv(fields []fieldInfo) {
+	for _, fi := range fields {
+		if fi.frozen {
+			continue
+		}
+
+		name := EnvName(s.envPrefix, fi.name)
+		if val, ok := s.env.LookupEnv(name); ok {
+			s.set(fi.name, val, Source{LayerEnv, name})
 		}
+	}
+}
 
-		val, ok := vals[name]
-		if !ok && optional {
+// applyOverrides overrides the values of fields with those in overrides.
+// Frozen fields can only be set from the file.
+func (s *readState) applyOverrides(fields []fieldInfo, overrides Values) {
+	for _, fi := range fields {
+		if fi.frozen {
 			continue
-		} else if !ok && !optional {
-			return fmt.Errorf(noField, path, name)
 		}
 
-		switch kind {
-		case reflect.Int:
-			intVal, err := strconv.ParseInt(val, 0, 64)
-			if err != nil {
-				return fmt.Errorf(
-					errorParsingConfig,
-					path,
-					err,
-				)
-			}
+		if val, ok := overrides[fi.name]; ok {
+			s.set(fi.name, val, Source{Layer: LayerOverride})
+		}
+	}
+}
 
-			if field.OverflowInt(intVal) {
-				return fmt.Errorf(
-					errorParsingConfig,
-					path,
-					fmt.Errorf(overflow, intVal),
-				)
-			}
+// checkUnknown returns an error for each key in s.vals which isn't the name of
+// one of fields, within the section being read, and isn't ignored.
+func (s *readState) checkUnknown(fields []fieldInfo, o *options) error {
+	known := make(map[string]bool, len(fields))
+	for _, fi := range fields {
+		known[fi.name] = true
+	}
 
-			field.SetInt(intVal)
-		case reflect.Uint:
-			intVal, err := strconv.ParseUint(val, 0, 64)
-			if err != nil {
-				return fmt.Errorf(
-					errorParsingConfig,
-					path,
-					err,
-				)
-			}
+	var keys []string
+	for key := range s.vals {
+		if known[key] || ignored(key, o.ignoreKeys) || hasAnyPrefix(key, o.allowedUnknown) {
+			continue
+		}
 
-			if field.OverflowUint(intVal) {
-				return fmt.Errorf(
-					errorParsingConfig,
-					path,
-					fmt.Errorf(overflow, intVal),
-				)
-			}
+		if o.section != "" && !strings.HasPrefix(key, o.section+".") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-			field.SetUint(intVal)
-		case reflect.String:
-			field.SetString(val)
-		case reflect.Float32, reflect.Float64:
-			floatVal, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				return fmt.Errorf(
-					errorParsingConfig,
-					path,
-					err,
-				)
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		errs[i] = newError(s.path, key, KindUnknown, fmt.Errorf(unknownKey, key))
+	}
+	return errors.Join(errs...)
+}
+
+// ignored reports whether key matches any of patterns.
+func ignored(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyPrefix reports whether key starts with any of prefixes.
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// bind sets each of the fields of v in plan from s.vals, and checks the
+// constraints between them.
+func (s *readState) bind(v reflect.Value, plan []fieldInfo) error {
+	if err := s.readFields(s.fieldValues(v, plan)); err != nil {
+		return err
+	}
+
+	return s.groups.check(s.path)
+}
+
+// fieldValues returns the fields of v in plan with their values filled in.
+// Pointers to nested structs are allocated if any of their options are set in
+// s.vals; otherwise their fields are left out, and aren't required.
+func (s *readState) fieldValues(v reflect.Value, plan []fieldInfo) []fieldInfo {
+	present := map[string]bool{}
+	for _, fi := range plan {
+		if _, ok := s.vals[fi.name]; ok {
+			for _, n := range fi.blocks {
+				present[fmt.Sprint(fi.index[:n])] = true
 			}
+		}
+	}
 
-			if field.OverflowFloat(floatVal) {
-				return fmt.Errorf(
-					errorParsingConfig,
-					path,
-					fmt.Errorf(overflow, floatVal),
-				)
+	fields := make([]fieldInfo, 0, len(plan))
+outer:
+	for _, fi := range plan {
+		field, parent := v, v
+		blocks := fi.blocks
+		for i, x := range fi.index {
+			parent = field
+			field = field.Field(x)
+			if len(blocks) == 0 || blocks[0] != i+1 {
+				continue
 			}
-		case reflect.Bool:
-			boolVal, err := strconv.ParseBool(val)
-			if err != nil {
-				return fmt.Errorf(
-					errorParsingConfig,
-					path,
-					err,
-				)
+
+			blocks = blocks[1:]
+			if !present[fmt.Sprint(fi.index[:i+1])] {
+				continue outer
 			}
 
-			field.SetBool(boolVal)
-		default:
-			anyVal := field.Interface()
-			p, ok := anyVal.(ValueParser)
-			if !ok {
-				return fmt.Errorf(
-					errorParsingConfig,
-					path,
-					fmt.Errorf(unsupported, typ.String()),
-				)
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
 			}
+			field = field.Elem()
+		}
 
-			if err := p.ParseConfigValue(val); err != nil {
-				return fmt.Errorf(
-					errorParsingConfig,
-					path,
-					err,
-				)
+		fi.v = field
+		if fi.setter {
+			fi.v = parent
+		}
+		fields = append(fields, fi)
+	}
+
+	return fields
+}
+
+// readFields sets each of fields from s.vals. Every missing option is
+// reported, but reading stops at any other error.
+func (s *readState) readFields(fields []fieldInfo) error {
+	var errs []error
+	for _, fi := range fields {
+		err := s.readOption(fi)
+		if err == nil {
+			continue
+		}
+
+		errs = append(errs, err)
+		if e, ok := err.(*Error); !ok || e.Kind != KindMissing {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readOption sets the field fi from s.vals.
+func (s *readState) readOption(fi fieldInfo) error {
+	name, optional := fi.name, fi.optional
+	val, ok := s.vals[name]
+	if s.cleared[name] && !fi.setter {
+		fi.v.SetZero()
+	}
+
+	if fi.cycle {
+		return newError(s.path, name, KindUnsupported,
+			fmt.Errorf("option %v holds a %v, which it is nested in", name, fi.f.Type))
+	}
+
+	if fi.raw && (fi.setter || fi.f.Type.Kind() != reflect.String) {
+		return newError(s.path, name, KindUnsupported,
+			fmt.Errorf("raw option %v must be a string, not %v", name, fi.f.Type))
+	}
+
+	if tag := fi.f.Tag.Get("group"); tag != "" {
+		if err := s.groups.add(tag, fi.parent, name, ok); err != nil {
+			return newError(s.path, name, KindUnsupported, err)
+		}
+		optional = true
+	}
+
+	if cond := fi.f.Tag.Get("requiredif"); !ok && cond != "" {
+		if !conditionHolds(s.vals, fi.parent, cond) {
+			return nil
+		}
+		return newError(s.path, name, KindMissing,
+			fmt.Errorf(noFieldIf, name, cond))
+	}
+
+	if !ok && optional {
+		return nil
+	} else if !ok {
+		return newError(s.path, name, KindMissing,
+			fmt.Errorf(noField, name))
+	}
+
+	if tag, ok := fi.f.Tag.Lookup("deprecated"); ok {
+		if err := s.deprecated(fi, tag); err != nil {
+			return err
+		}
+	}
+
+	if format := fi.f.Tag.Get("number"); format != "" {
+		var err error
+		if val, err = s.number(fi, format, val); err != nil {
+			return err
+		}
+	}
+
+	// An optional option set to an empty value, as Write writes an unset
+	// string, is left unset rather than validated.
+	if tag := fi.f.Tag.Get("validate"); tag != "" && !(optional && val == "") {
+		if err := validate(tag, val, s.fsys); err != nil {
+			return invalidError(s.path, name, err)
+		}
+	}
+
+	return s.convert(fi, val)
+}
+
+// convert sets the field fi to val, timing the conversion if s.timings is
+// set.
+func (s *readState) convert(fi fieldInfo, val string) error {
+	if s.timings != nil {
+		start := time.Now()
+		defer func() {
+			s.timings[fi.name] = time.Since(start)
+		}()
+	}
+
+	if fi.raw {
+		fi.v.SetString(val)
+		return nil
+	}
+
+	if fi.setter {
+		setter := fi.v.Addr().Interface().(FieldSetter)
+		if err := setter.SetConfigField(fi.local, val); err != nil {
+			return invalidError(s.path, fi.name, err)
+		}
+		return nil
+	}
+
+	// A value changed by the number tag replaces the original for parsers
+	// which read it from the values themselves, such as Lazy.
+	vals := s.vals
+	if val != vals[fi.name] {
+		vals = maps.Clone(vals)
+		vals[fi.name] = val
+	}
+
+	if err := readField(fi.name, val, vals, fi.v); err != nil {
+		if errors.As(err, &unsupportedTypeError{}) {
+			err := newError(s.path, fi.name, KindUnsupported, err)
+			if s.allowUnsupported {
+				s.unsupported = append(s.unsupported, err)
+				return nil
 			}
-			field.Set(reflect.ValueOf(p).Elem())
+			return err
 		}
+		return invalidError(s.path, fi.name, err)
 	}
 	return nil
 }
 
+// unsupportedTypeError is returned by readField for types it doesn't know how
+// to parse.
+type unsupportedTypeError struct {
+	typ reflect.Type
+}
+
+func (e unsupportedTypeError) Error() string {
+	return fmt.Sprintf(unsupported, e.typ.String())
+}
+
+// readField converts val, the value of the option name, and stores it in
+// field.
+func readField(name, val string, vals Values, field reflect.Value) error {
+	typ := field.Type()
+	if p, ok := implementation(field, contextValueParserType); ok {
+		return p.(ContextValueParser).ParseConfigValue(name, vals)
+	}
+
+	if p, ok := implementation(field, valueParserType); ok {
+		return p.(ValueParser).ParseConfigValue(val)
+	}
+
+	if parse, ok := lookupParser(typ); ok {
+		x, err := parse(val)
+		if err != nil {
+			return err
+		}
+
+		field.Set(reflect.ValueOf(x))
+		return nil
+	}
+
+	if names, ok := lookupEnum(typ); ok {
+		enumVal, err := parseEnum(names, val)
+		if err != nil {
+			return err
+		}
+
+		field.Set(enumVal)
+		return nil
+	}
+
+	if u, ok := implementation(field, textUnmarshalerType); ok {
+		return u.(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	if parse, ok := kindParsers[typ.Kind()]; ok {
+		return parse(val, field)
+	}
+
+	return unsupportedTypeError{typ}
+}
+
+var (
+	valueParserType        = reflect.TypeFor[ValueParser]()
+	contextValueParserType = reflect.TypeFor[ContextValueParser]()
+	textUnmarshalerType    = reflect.TypeFor[encoding.TextUnmarshaler]()
+	fieldSetterType        = reflect.TypeFor[FieldSetter]()
+)
+
+// implementation returns the value which parses into field, if field's type
+// implements iface either directly or through a pointer. A nil pointer field
+// is allocated first, so that pointer-receiver methods can be used for pointer
+// fields.
+func implementation(field reflect.Value, iface reflect.Type) (any, bool) {
+	if field.CanAddr() && field.Addr().Type().Implements(iface) {
+		return field.Addr().Interface(), true
+	}
+
+	if field.Kind() == reflect.Pointer && field.Type().Implements(iface) {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return field.Interface(), true
+	}
+
+	return nil, false
+}
+
+// hasParser reports whether the type t parses itself, in which case it
+// shouldn't be treated as a struct of options even if it is one.
+func hasParser(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(valueParserType) ||
+		ptr.Implements(contextValueParserType) ||
+		ptr.Implements(textUnmarshalerType) ||
+		hasConversion(t)
+}
+
+// conditionHolds reports whether the condition from a `requiredif` tag is
+// satisfied by vals. The condition is either `key=value`, which holds when key
+// is set to exactly value, or just `key`, which holds when key is set at all.
+// If value is `true` or `false`, key is compared as a bool, so any spelling
+// strconv.ParseBool accepts matches. key is relative to prefix, the name of
+// the struct holding the field.
+func conditionHolds(vals Values, prefix, cond string) bool {
+	key, want, hasValue := strings.Cut(cond, "=")
+	got, ok := vals[joinName(prefix, strings.TrimSpace(key))]
+	if !ok {
+		return false
+	} else if !hasValue {
+		return true
+	}
+
+	want = strings.TrimSpace(want)
+	if strings.EqualFold(want, "true") || strings.EqualFold(want, "false") {
+		gotBool, err := strconv.ParseBool(got)
+		return err == nil && gotBool == strings.EqualFold(want, "true")
+	}
+
+	return got == want
+}
+
 func toSnakeCase(x string) string {
 	var b strings.Builder
 	for i, c := range x {
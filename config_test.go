@@ -1,6 +1,10 @@
 package config_test
 
 import (
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -12,14 +16,14 @@ func TestSimpleReflect(t *testing.T) {
 		Cool string
 	}
 	err := config.Read("<input>", strings.NewReader(`
-	`), &conf)
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
 	if err == nil {
 		t.Fatal("expected error, found no error")
 	}
 
 	err = config.Read("<input>", strings.NewReader(`
 	cool = beans
-	`), &conf)
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
 	if err != nil {
 		t.Fatalf("failed to parse config into struct: %v", err)
 	}
@@ -30,7 +34,7 @@ func TestSimpleReflect(t *testing.T) {
 
 	err = config.Read("<input>", strings.NewReader(`
 	cool = beans # comment
-	`), &conf)
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
 	if err != nil {
 		t.Fatalf("failed to parse config into struct: %v", err)
 	}
@@ -45,14 +49,14 @@ func TestRenameReflect(t *testing.T) {
 		Cool string `config:"coolio"`
 	}
 	err := config.Read("<input>", strings.NewReader(`
-	`), &conf)
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
 	if err == nil {
 		t.Fatal("expected error, found no error")
 	}
 
 	err = config.Read("<input>", strings.NewReader(`
 	coolio = beans
-	`), &conf)
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
 	if err != nil {
 		t.Fatalf("failed to parse config into struct: %v", err)
 	}
@@ -63,7 +67,7 @@ func TestRenameReflect(t *testing.T) {
 
 	err = config.Read("<input>", strings.NewReader(`
 	coolio = beans # comment
-	`), &conf)
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
 	if err != nil {
 		t.Fatalf("failed to parse config into struct: %v", err)
 	}
@@ -78,14 +82,14 @@ func TestOptionalReflect(t *testing.T) {
 		Cool string `config:"coolio,optional"`
 	}
 	err := config.Read("<input>", strings.NewReader(`
-	`), &conf)
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
 	if err != nil {
 		t.Fatalf("failed to parse: %v", err)
 	}
 
 	err = config.Read("<input>", strings.NewReader(`
 	coolio = beans
-	`), &conf)
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
 	if err != nil {
 		t.Fatalf("failed to parse config into struct: %v", err)
 	}
@@ -96,7 +100,7 @@ func TestOptionalReflect(t *testing.T) {
 
 	err = config.Read("<input>", strings.NewReader(`
 	coolio = beans # comment
-	`), &conf)
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
 	if err != nil {
 		t.Fatalf("failed to parse config into struct: %v", err)
 	}
@@ -166,3 +170,656 @@ func TestKeyValueCommentMap(t *testing.T) {
 		t.Fatalf(`expected "beans", found "%v"`, value)
 	}
 }
+
+func TestRequiredIfReflect(t *testing.T) {
+	var conf struct {
+		TLSEnabled bool   `config:"tls_enabled"`
+		TLSCert    string `config:"tls_cert" requiredif:"tls_enabled=true"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	tls_enabled = false
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	tls_enabled = true
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	tls_enabled = true
+	tls_cert = cert.pem
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.TLSCert != "cert.pem" {
+		t.Fatalf(`expected "cert.pem", found "%v"`, conf.TLSCert)
+	}
+}
+
+func TestRequiredIfBool(t *testing.T) {
+	var conf struct {
+		TLSEnabled bool   `config:"tls_enabled"`
+		TLSCert    string `config:"tls_cert,optional" requiredif:"tls_enabled=true"`
+	}
+	tests := []struct {
+		value    string
+		required bool
+	}{
+		{"true", true},
+		{"TRUE", true},
+		{"1", true},
+		{"t", true},
+		{"false", false},
+		{"0", false},
+	}
+
+	for _, test := range tests {
+		err := config.Read("<input>", strings.NewReader("tls_enabled = "+test.value), &conf,
+			config.WithEnvironment(config.EnvMap{}))
+		if test.required && err == nil {
+			t.Fatalf("expected tls_cert to be required for %q", test.value)
+		} else if !test.required && err != nil {
+			t.Fatalf("expected tls_cert to be optional for %q: %v", test.value, err)
+		}
+	}
+}
+
+type requiredIfTLS struct {
+	CertFile string `config:"tls_cert_file,optional" requiredif:"tls_key_file"`
+	KeyFile  string `config:"tls_key_file,optional" requiredif:"tls_cert_file"`
+}
+
+func TestRequiredIfNested(t *testing.T) {
+	var conf struct {
+		Server requiredIfTLS `config:"server,optional"`
+	}
+	input := "server.tls_cert_file = cert.pem"
+	err := config.Read("<input>", strings.NewReader(input), &conf,
+		config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "server.tls_key_file" || errs[0].Kind != config.KindMissing {
+		t.Fatalf("expected server.tls_key_file to be missing, found %v", err)
+	}
+
+	vals, err := config.Parse("<input>", strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	var section requiredIfTLS
+	err = config.ReadSection(vals, "server", &section, config.WithEnvironment(config.EnvMap{}))
+	errs = config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "server.tls_key_file" || errs[0].Kind != config.KindMissing {
+		t.Fatalf("expected server.tls_key_file to be missing, found %v", err)
+	}
+}
+
+func TestEnvOverride(t *testing.T) {
+	t.Setenv("ENV_OVERRIDE_PORT", "9090")
+	t.Setenv("ENV_OVERRIDE_HOST", "example.com")
+
+	var conf struct {
+		Port int    `config:"env_override_port"`
+		Host string `config:"env_override_host,optional"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	env_override_port = 8080
+	`), &conf)
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Port != 9090 {
+		t.Fatalf("expected 9090, found %v", conf.Port)
+	}
+
+	if conf.Host != "example.com" {
+		t.Fatalf(`expected "example.com", found "%v"`, conf.Host)
+	}
+}
+
+func TestFrozen(t *testing.T) {
+	t.Setenv("FROZEN_AUDIT_LOG", "/dev/null")
+
+	var conf struct {
+		AuditLog string `config:"frozen_audit_log,frozen"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	frozen_audit_log = /var/log/audit.log
+	`), &conf)
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.AuditLog != "/var/log/audit.log" {
+		t.Fatalf(`expected "/var/log/audit.log", found "%v"`, conf.AuditLog)
+	}
+
+	err = config.Read("<input>", strings.NewReader(""), &conf)
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	t.Setenv("PRECEDENCE_PORT", "9090")
+
+	var conf struct {
+		Port int `config:"precedence_port"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	precedence_port = 8080
+	`), &conf, config.WithPrecedence(config.LayerEnv, config.LayerFile))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Port != 8080 {
+		t.Fatalf("expected 8080, found %v", conf.Port)
+	}
+
+	err = config.Read("<input>", strings.NewReader(""), &conf,
+		config.WithPrecedence(config.LayerFile))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	err = config.Read("<input>", nil, &conf,
+		config.WithPrecedence(config.LayerEnv))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Port != 9090 {
+		t.Fatalf("expected 9090, found %v", conf.Port)
+	}
+}
+
+func TestReadEnv(t *testing.T) {
+	t.Setenv("READ_ENV_PORT", "9090")
+
+	var conf struct {
+		Port  int    `config:"read_env_port"`
+		Host  string `config:"read_env_host,optional"`
+		Debug bool   `config:"read_env_debug,optional"`
+	}
+	conf.Host = "localhost"
+	if err := config.ReadEnv(&conf); err != nil {
+		t.Fatalf("failed to read environment into struct: %v", err)
+	}
+
+	if conf.Port != 9090 {
+		t.Fatalf("expected 9090, found %v", conf.Port)
+	}
+
+	if conf.Host != "localhost" {
+		t.Fatalf(`expected "localhost", found "%v"`, conf.Host)
+	}
+
+	var missing struct {
+		Name string `config:"read_env_name"`
+	}
+	if err := config.ReadEnv(&missing); err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	t.Setenv("OVERRIDES_PORT", "9090")
+
+	var conf struct {
+		Port     int    `config:"overrides_port"`
+		AuditLog string `config:"overrides_audit_log,frozen"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	overrides_port = 8080
+	overrides_audit_log = /var/log/audit.log
+	`), &conf, config.WithOverrides(config.Values{
+		"overrides_port":      "7070",
+		"overrides_audit_log": "/dev/null",
+	}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Port != 7070 {
+		t.Fatalf("expected 7070, found %v", conf.Port)
+	}
+
+	if conf.AuditLog != "/var/log/audit.log" {
+		t.Fatalf(`expected "/var/log/audit.log", found "%v"`, conf.AuditLog)
+	}
+}
+
+func TestFields(t *testing.T) {
+	var conf struct {
+		Port  int    `config:"port" comment:"The port to listen on."`
+		Token string `config:"token,optional,secret"`
+	}
+	fields, err := config.Fields(&conf)
+	if err != nil {
+		t.Fatalf("failed to get fields: %v", err)
+	}
+
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, found %v", len(fields))
+	}
+
+	if fields[0].Name != "port" || fields[0].Type.Kind() != reflect.Int {
+		t.Fatalf("expected port of kind int, found %v of kind %v", fields[0].Name, fields[0].Type.Kind())
+	}
+
+	if fields[0].Tag.Get("comment") != "The port to listen on." {
+		t.Fatalf(`expected "The port to listen on.", found "%v"`, fields[0].Tag.Get("comment"))
+	}
+
+	if !fields[1].Optional || !fields[1].Secret {
+		t.Fatalf("expected token to be optional and secret, found %+v", fields[1])
+	}
+}
+
+type redisConfig struct {
+	Host string
+	Port int `config:"port,optional"`
+}
+
+func TestNested(t *testing.T) {
+	var conf struct {
+		Name  string
+		Cache redisConfig  `config:"cache"`
+		Queue *redisConfig `config:"queue"`
+		Jobs  *redisConfig `config:"jobs"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	name = app
+	cache.host = localhost
+	cache.port = 6379
+	queue.host = queue.example.com
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Cache.Host != "localhost" || conf.Cache.Port != 6379 {
+		t.Fatalf("expected {localhost 6379}, found %+v", conf.Cache)
+	}
+
+	if conf.Queue == nil || conf.Queue.Host != "queue.example.com" {
+		t.Fatalf("expected queue.example.com, found %+v", conf.Queue)
+	}
+
+	if conf.Jobs != nil {
+		t.Fatalf("expected nil, found %+v", conf.Jobs)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	name = app
+	cache.host = localhost
+	jobs.port = 6379
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "jobs.host" || errs[0].Kind != config.KindMissing {
+		t.Fatalf("expected jobs.host to be missing, found %v", err)
+	}
+	var incomplete struct {
+		Jobs *jobsConfig `config:"jobs"`
+	}
+	err = config.Read("<input>", strings.NewReader(`
+	jobs.other = 1
+	`), &incomplete, config.WithEnvironment(config.EnvMap{}))
+	errs = config.Errors(err)
+	if len(errs) != 2 || errs[0].Key != "jobs.host" || errs[1].Key != "jobs.port" {
+		t.Fatalf("expected jobs.host and jobs.port to be missing, found %v", err)
+	}
+}
+
+type jobsConfig struct {
+	Host  string
+	Port  int
+	Other int `config:"other,optional"`
+}
+
+type cycleNode struct {
+	Name string
+	Next *cycleNode `config:",optional"`
+}
+
+func TestNestedCycle(t *testing.T) {
+	var conf struct {
+		Root cycleNode `config:"root"`
+	}
+	fields, err := config.Fields(&conf)
+	if err != nil {
+		t.Fatalf("failed to get fields: %v", err)
+	}
+
+	if len(fields) != 2 || fields[1].Name != "root.next" {
+		t.Fatalf("expected root.name and root.next, found %v", fields)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	root.name = a
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "root.next" || errs[0].Kind != config.KindUnsupported {
+		t.Fatalf("expected root.next to be unsupported, found %v", err)
+	}
+
+	var report config.Report
+	config.Read("<input>", strings.NewReader(""), &conf, config.WithReport(&report))
+	if len(report.Skipped) != 0 {
+		t.Fatalf("expected no skipped fields, found %v", report.Skipped)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	var conf struct {
+		Cache  redisConfig `config:",prefix=redis"`
+		Inline redisConfig `config:",prefix="`
+		Name   string      `config:",optional"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	redis.host = cache.example.com
+	host = localhost
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Cache.Host != "cache.example.com" {
+		t.Fatalf(`expected "cache.example.com", found "%v"`, conf.Cache.Host)
+	}
+
+	if conf.Inline.Host != "localhost" {
+		t.Fatalf(`expected "localhost", found "%v"`, conf.Inline.Host)
+	}
+}
+
+func TestReadSection(t *testing.T) {
+	vals, err := config.Parse("<input>", strings.NewReader(`
+	metrics.port = 9100
+	server.port = 8080
+	`))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	var metrics, server struct {
+		Port int
+	}
+	if err := config.ReadSection(vals, "metrics", &metrics,
+		config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read section: %v", err)
+	}
+
+	if err := config.ReadSection(vals, "server", &server,
+		config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read section: %v", err)
+	}
+
+	if metrics.Port != 9100 || server.Port != 8080 {
+		t.Fatalf("expected 9100 and 8080, found %v and %v", metrics.Port, server.Port)
+	}
+
+	err = config.ReadSection(vals, "admin", &server, config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "admin.port" {
+		t.Fatalf("expected admin.port to be missing, found %v", err)
+	}
+}
+
+func TestAllowExport(t *testing.T) {
+	vals, err := config.Parse("<input>", strings.NewReader(`
+	export PORT=8080
+	export	HOST = "localhost"
+	export = yes
+	exported = no
+	`), config.AllowExport())
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	expected := config.Values{"PORT": "8080", "HOST": "localhost", "export": "yes", "exported": "no"}
+	if !maps.Equal(vals, expected) {
+		t.Fatalf("expected %v, found %v", expected, vals)
+	}
+
+	vals, err = config.Parse("<input>", strings.NewReader("export PORT=8080"))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	if vals["export PORT"] != "8080" {
+		t.Fatalf(`expected "export PORT" to be set without AllowExport, found %v`, vals)
+	}
+}
+
+func TestAllowReferences(t *testing.T) {
+	input := `
+	db.primary.host = db1
+	db.primary.port = 5432
+	db.primary.user = app
+	db.replica = *db.primary
+	db.replica.host = db2
+	pattern = "*db.primary"
+	`
+	vals, err := config.Parse("<input>", strings.NewReader(input), config.AllowReferences())
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	expected := config.Values{
+		"db.primary.host": "db1",
+		"db.primary.port": "5432",
+		"db.primary.user": "app",
+		"db.replica.host": "db2",
+		"db.replica.port": "5432",
+		"db.replica.user": "app",
+		"pattern":         "*db.primary",
+	}
+	if !maps.Equal(vals, expected) {
+		t.Fatalf("expected %v, found %v", expected, vals)
+	}
+
+	_, err = config.Parse("<input>", strings.NewReader("a = *b\nb.c = 1"), config.AllowReferences())
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Line != 1 || errs[0].Kind != config.KindSyntax {
+		t.Fatalf("expected a syntax error on line 1, found %v", err)
+	}
+}
+
+func TestAllowBareKeys(t *testing.T) {
+	input := `
+	verbose
+	dry_run # comment
+	port = 8080
+	`
+	vals, err := config.Parse("<input>", strings.NewReader(input), config.AllowBareKeys())
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	expected := config.Values{"verbose": "true", "dry_run": "true", "port": "8080"}
+	if !maps.Equal(vals, expected) {
+		t.Fatalf("expected %v, found %v", expected, vals)
+	}
+
+	if _, err := config.Parse("<input>", strings.NewReader(input)); err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestParseEntries(t *testing.T) {
+	entries, err := config.ParseEntries("<input>", strings.NewReader(`
+	# The old name for port.
+	# config:deprecated
+	listen = 8080
+	password = "hunter2" # config:secret, final
+
+	# config:final
+
+	port = 8080
+	port = 9090
+	`))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, found %v", len(entries))
+	}
+
+	if entries[0].Key != "listen" || entries[0].Line != 4 || !slices.Equal(entries[0].Directives, []string{"deprecated"}) {
+		t.Fatalf("expected listen on line 4 to be deprecated, found %+v", entries[0])
+	}
+
+	if !slices.Equal(entries[1].Directives, []string{"secret", "final"}) {
+		t.Fatalf("expected [secret final], found %v", entries[1].Directives)
+	}
+
+	if entries[2].Directives != nil || entries[3].Value != "9090" {
+		t.Fatalf("expected port without directives set twice, found %+v and %+v", entries[2], entries[3])
+	}
+}
+
+func TestReadValues(t *testing.T) {
+	t.Setenv("READ_VALUES_PORT", "9090")
+
+	var conf struct {
+		Port int `config:"read_values_port"`
+	}
+	vals, err := config.ReadValues("<input>", strings.NewReader(`
+	read_values_port = 8080
+	plugin.name = metrics
+	`), &conf)
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	expected := config.Values{"read_values_port": "9090", "plugin.name": "metrics"}
+	if !maps.Equal(vals, expected) {
+		t.Fatalf("expected %v, found %v", expected, vals)
+	}
+}
+
+// account keeps its fields unexported, and sets them itself.
+type account struct {
+	Name  string `config:"account_name"`
+	id    int    `config:"account_id"`
+	token string `config:"account_token,optional"`
+	cache map[string]string
+}
+
+func (a *account) SetConfigField(name, value string) error {
+	switch name {
+	case "account_id":
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		a.id = id
+	case "account_token":
+		a.token = value
+	}
+	return nil
+}
+
+func TestFieldSetter(t *testing.T) {
+	var conf struct {
+		Account account `config:",prefix="`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	account_name = alice
+	account_id = 42
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Account.Name != "alice" || conf.Account.id != 42 {
+		t.Fatalf("expected {alice 42}, found %+v", conf.Account)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	account_name = alice
+	account_id = forty-two
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "account_id" || errs[0].Kind != config.KindInvalid {
+		t.Fatalf("expected account_id to be invalid, found %v", err)
+	}
+
+	var b strings.Builder
+	if err := config.Write(&b, &conf); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if b.String() != "account_name = alice\n" {
+		t.Fatalf(`expected only account_name to be written, found "%v"`, b.String())
+	}
+}
+
+func TestSkipTag(t *testing.T) {
+	var conf struct {
+		Port  int
+		Cache map[string]string `config:"-"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	port = 8080
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Port != 8080 {
+		t.Fatalf("expected 8080, found %v", conf.Port)
+	}
+}
+
+func TestAllowUnsupported(t *testing.T) {
+	var conf struct {
+		Port  int
+		Cache map[string]string `config:"cache"`
+		Done  chan bool         `config:"done,optional"`
+	}
+	input := `
+	port = 8080
+	cache = a
+	`
+	err := config.Read("<input>", strings.NewReader(input), &conf, config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "cache" || errs[0].Kind != config.KindUnsupported {
+		t.Fatalf("expected cache to be unsupported, found %v", err)
+	}
+
+	var report config.Report
+	err = config.Read("<input>", strings.NewReader(input), &conf,
+		config.AllowUnsupported(), config.WithReport(&report), config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Port != 8080 || conf.Cache != nil {
+		t.Fatalf("expected only port to be set, found %+v", conf)
+	}
+
+	if len(report.Unsupported) != 1 || report.Unsupported[0].Key != "cache" {
+		t.Fatalf("expected cache to be reported as unsupported, found %v", report.Unsupported)
+	}
+
+	var bad struct {
+		Port int `config:"port"`
+	}
+	err = config.Read("<input>", strings.NewReader("port = x"), &bad, config.AllowUnsupported(),
+		config.WithEnvironment(config.EnvMap{}))
+	if errs := config.Errors(err); len(errs) != 1 || errs[0].Kind != config.KindInvalid {
+		t.Fatalf("expected an invalid port, found %v", err)
+	}
+}
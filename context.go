@@ -0,0 +1,21 @@
+package config
+
+import "context"
+
+// contextKey is the key a config of type T is stored under in a context, so
+// that configs of different types don't overwrite each other.
+type contextKey[T any] struct{}
+
+// NewContext returns a copy of ctx carrying cfg, which can be retrieved with
+// [config.FromContext]. This lets request handlers reach the config without a
+// package-level variable. The config shouldn't be modified once stored.
+func NewContext[T any](ctx context.Context, cfg *T) context.Context {
+	return context.WithValue(ctx, contextKey[T]{}, cfg)
+}
+
+// FromContext returns the config of type T stored in ctx by
+// [config.NewContext], and whether there was one.
+func FromContext[T any](ctx context.Context) (*T, bool) {
+	cfg, ok := ctx.Value(contextKey[T]{}).(*T)
+	return cfg, ok
+}
@@ -0,0 +1,34 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+type serverConfig struct {
+	Port int
+}
+
+type clientConfig struct {
+	Port int
+}
+
+func TestContext(t *testing.T) {
+	ctx := config.NewContext(context.Background(), &serverConfig{Port: 8080})
+	ctx = config.NewContext(ctx, &clientConfig{Port: 9090})
+
+	server, ok := config.FromContext[serverConfig](ctx)
+	if !ok {
+		t.Fatal("expected server config in context")
+	}
+
+	if server.Port != 8080 {
+		t.Fatalf("expected 8080, found %v", server.Port)
+	}
+
+	if _, ok := config.FromContext[int](ctx); ok {
+		t.Fatal("expected no int config in context")
+	}
+}
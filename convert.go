@@ -0,0 +1,217 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+var (
+	typesMu sync.RWMutex
+	// types holds the conversions registered with RegisterType.
+	types = map[reflect.Type]typeConversion{}
+)
+
+// typeConversion converts text to a value of a particular type, and back.
+type typeConversion struct {
+	parse func(string) (any, error)
+	// format is nil if the type can't be written.
+	format func(any) string
+}
+
+// RegisterType registers the conversion from text to values of type T used
+// for fields of that type, and the conversion back to text used by
+// [config.Write], which may be nil. This allows types from other packages to
+// be read without wrapping them in a type implementing
+// [config.ValueParser]. For example:
+//
+//	func init() {
+//		config.RegisterType(semver.NewVersion, (*semver.Version).String)
+//	}
+//
+// A registered conversion is used in preference to the built-in conversions
+// for the type, but types implementing [config.ValueParser] or
+// [config.ContextValueParser] still parse themselves. Since a struct type is
+// read as a nested struct unless it has a conversion, types should be
+// registered before any struct containing them is read, for example from an
+// init function. Registering the same type again replaces its conversion.
+func RegisterType[T any](parse func(string) (T, error), format func(T) string) {
+	c := typeConversion{
+		parse: func(s string) (any, error) {
+			return parse(s)
+		},
+	}
+	if format != nil {
+		c.format = func(x any) string {
+			return format(x.(T))
+		}
+	}
+
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	types[reflect.TypeFor[T]()] = c
+}
+
+// lookupParser returns the conversion from text to values of type t, from
+// RegisterType or the built-in conversions.
+func lookupParser(t reflect.Type) (func(string) (any, error), bool) {
+	typesMu.RLock()
+	c, ok := types[t]
+	typesMu.RUnlock()
+	if ok {
+		return c.parse, true
+	}
+
+	parse, ok := builtinTypes[t]
+	return parse, ok
+}
+
+// hasConversion reports whether values of type t are converted by
+// RegisterType or the built-in conversions.
+func hasConversion(t reflect.Type) bool {
+	_, ok := lookupParser(t)
+	return ok
+}
+
+// lookupFormatter returns the conversion from values of type t to text, from
+// RegisterType or the built-in conversions.
+func lookupFormatter(t reflect.Type) (func(any) string, bool) {
+	typesMu.RLock()
+	c, ok := types[t]
+	typesMu.RUnlock()
+	if ok {
+		return c.format, c.format != nil
+	}
+
+	format, ok := builtinWriters[t]
+	return format, ok
+}
+
+// kindParsers holds the conversions from text for the types which don't have
+// one of their own, by kind.
+var kindParsers = map[reflect.Kind]func(val string, field reflect.Value) error{
+	reflect.Int:        parseInt,
+	reflect.Int8:       parseInt,
+	reflect.Int16:      parseInt,
+	reflect.Int32:      parseInt,
+	reflect.Int64:      parseInt,
+	reflect.Uint:       parseUint,
+	reflect.Uint8:      parseUint,
+	reflect.Uint16:     parseUint,
+	reflect.Uint32:     parseUint,
+	reflect.Uint64:     parseUint,
+	reflect.Uintptr:    parseUint,
+	reflect.String:     parseString,
+	reflect.Float32:    parseFloat,
+	reflect.Float64:    parseFloat,
+	reflect.Complex64:  parseComplex,
+	reflect.Complex128: parseComplex,
+	reflect.Bool:       parseBool,
+}
+
+func parseInt(val string, field reflect.Value) error {
+	intVal, err := strconv.ParseInt(val, 0, 64)
+	if err != nil {
+		return err
+	}
+
+	if field.OverflowInt(intVal) {
+		return fmt.Errorf(overflow, intVal)
+	}
+
+	field.SetInt(intVal)
+	return nil
+}
+
+func parseUint(val string, field reflect.Value) error {
+	intVal, err := strconv.ParseUint(val, 0, 64)
+	if err != nil {
+		return err
+	}
+
+	if field.OverflowUint(intVal) {
+		return fmt.Errorf(overflow, intVal)
+	}
+
+	field.SetUint(intVal)
+	return nil
+}
+
+func parseString(val string, field reflect.Value) error {
+	field.SetString(val)
+	return nil
+}
+
+func parseFloat(val string, field reflect.Value) error {
+	floatVal, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return err
+	}
+
+	if field.OverflowFloat(floatVal) {
+		return fmt.Errorf(overflow, floatVal)
+	}
+
+	field.SetFloat(floatVal)
+	return nil
+}
+
+func parseComplex(val string, field reflect.Value) error {
+	complexVal, err := strconv.ParseComplex(val, field.Type().Bits())
+	if err != nil {
+		return err
+	}
+
+	field.SetComplex(complexVal)
+	return nil
+}
+
+func parseBool(val string, field reflect.Value) error {
+	boolVal, err := strconv.ParseBool(val)
+	if err != nil {
+		return err
+	}
+
+	field.SetBool(boolVal)
+	return nil
+}
+
+// kindFormatters holds the conversions to text for the kinds in kindParsers.
+var kindFormatters = map[reflect.Kind]func(field reflect.Value) string{
+	reflect.Int:        formatInt,
+	reflect.Int8:       formatInt,
+	reflect.Int16:      formatInt,
+	reflect.Int32:      formatInt,
+	reflect.Int64:      formatInt,
+	reflect.Uint:       formatUint,
+	reflect.Uint8:      formatUint,
+	reflect.Uint16:     formatUint,
+	reflect.Uint32:     formatUint,
+	reflect.Uint64:     formatUint,
+	reflect.Uintptr:    formatUint,
+	reflect.String:     reflect.Value.String,
+	reflect.Float32:    formatFloat,
+	reflect.Float64:    formatFloat,
+	reflect.Complex64:  formatComplex,
+	reflect.Complex128: formatComplex,
+	reflect.Bool: func(field reflect.Value) string {
+		return strconv.FormatBool(field.Bool())
+	},
+}
+
+func formatInt(field reflect.Value) string {
+	return strconv.FormatInt(field.Int(), 10)
+}
+
+func formatUint(field reflect.Value) string {
+	return strconv.FormatUint(field.Uint(), 10)
+}
+
+func formatFloat(field reflect.Value) string {
+	return strconv.FormatFloat(field.Float(), 'g', -1, field.Type().Bits())
+}
+
+func formatComplex(field reflect.Value) string {
+	return strconv.FormatComplex(field.Complex(), 'g', -1, field.Type().Bits())
+}
@@ -0,0 +1,80 @@
+package config_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+// rgb is a struct from "another package" which doesn't parse itself.
+type rgb struct {
+	R, G, B uint8
+}
+
+func init() {
+	config.RegisterType(func(s string) (rgb, error) {
+		var c rgb
+		_, err := fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B)
+		return c, err
+	}, func(c rgb) string {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	})
+}
+
+func TestRegisterType(t *testing.T) {
+	var conf struct {
+		Background rgb
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	background = "#1e90ff"
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Background != (rgb{0x1e, 0x90, 0xff}) {
+		t.Fatalf("expected {30 144 255}, found %v", conf.Background)
+	}
+
+	var b strings.Builder
+	if err := config.Write(&b, &conf); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	expected := "background = \"#1e90ff\"\n"
+	if b.String() != expected {
+		t.Fatalf(`expected "%v", found "%v"`, expected, b.String())
+	}
+}
+
+func TestSizedInts(t *testing.T) {
+	var conf struct {
+		Small  int8
+		Port   uint16
+		Offset int64
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	small = -128
+	port = 0x1f90
+	offset = -9000000000
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Small != -128 || conf.Port != 8080 || conf.Offset != -9000000000 {
+		t.Fatalf("expected {-128 8080 -9000000000}, found %+v", conf)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	small = 128
+	port = 80
+	offset = 0
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "small" || errs[0].Kind != config.KindInvalid {
+		t.Fatalf("expected small to overflow, found %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Deprecation describes a deprecated option which was set, as given to
+// [config.OnDeprecated]. Options are deprecated with the `deprecated:""`
+// struct tag, which takes a comma separated list of `since=version`, the
+// version the option was deprecated in, `remove=version`, the version it
+// stops being accepted in, and `use=name`, the option replacing it. For
+// example, `deprecated:"since=1.4,remove=2.0,use=listen_addr"`.
+type Deprecation struct {
+	Key    string
+	Since  string
+	Remove string
+	Use    string
+	// Source is where the option was set.
+	Source Source
+}
+
+func (d Deprecation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "option %v is deprecated", d.Key)
+	if d.Since != "" {
+		fmt.Fprintf(&b, " since version %v", d.Since)
+	}
+	if d.Remove != "" {
+		fmt.Fprintf(&b, " and will be removed in version %v", d.Remove)
+	}
+	if d.Use != "" {
+		fmt.Fprintf(&b, ", use %v instead", d.Use)
+	}
+	return b.String()
+}
+
+// WithVersion sets the version of the program reading the config, which
+// decides whether deprecated options are still accepted: an option set in a
+// version at or after the one it's removed in is an error of kind
+// KindRemoved. Without it, deprecated options are always accepted.
+func WithVersion(version string) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// OnDeprecated makes reading call fn for each deprecated option which is set
+// and still accepted, for example to log a warning. Deprecated options are
+// also listed in the [Report].
+func OnDeprecated(fn func(Deprecation)) Option {
+	return func(o *options) {
+		o.onDeprecated = fn
+	}
+}
+
+// parseDeprecated returns the Deprecation described by the `deprecated:""`
+// struct tag tag, for the option key.
+func parseDeprecated(key, tag string) (Deprecation, error) {
+	d := Deprecation{Key: key}
+	for _, x := range strings.Split(tag, ",") {
+		name, val, _ := strings.Cut(strings.TrimSpace(x), "=")
+		switch name {
+		case "since":
+			d.Since = val
+		case "remove":
+			d.Remove = val
+		case "use":
+			d.Use = val
+		case "":
+		default:
+			return Deprecation{}, fmt.Errorf("unknown deprecation '%v' for %v", name, key)
+		}
+	}
+	return d, nil
+}
+
+// deprecated records that the deprecated option fi was set, or returns an
+// error if it has been removed.
+func (s *readState) deprecated(fi fieldInfo, tag string) error {
+	d, err := parseDeprecated(fi.name, tag)
+	if err != nil {
+		return newError(s.path, fi.name, KindUnsupported, err)
+	}
+	d.Source = s.sources[fi.name]
+
+	if d.Remove != "" && s.version != "" && compareVersions(s.version, d.Remove) >= 0 {
+		msg := fmt.Sprintf("option %v was removed in version %v", d.Key, d.Remove)
+		if d.Use != "" {
+			msg += fmt.Sprintf(", use %v instead", d.Use)
+		}
+		return newError(s.path, fi.name, KindRemoved, errors.New(msg))
+	}
+
+	s.deprecations = append(s.deprecations, d)
+	if s.onDeprecated != nil {
+		s.onDeprecated(d)
+	}
+	return nil
+}
+
+// compareVersions compares the dotted versions a and b, such as `1.10.2`,
+// returning -1, 0 or 1. A leading `v` is ignored. Numeric parts are compared
+// as numbers and others as text, and missing parts count as 0.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(as), len(bs)); i += 1 {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		xn, xerr := strconv.ParseUint(x, 10, 64)
+		yn, yerr := strconv.ParseUint(y, 10, 64)
+		switch {
+		case xerr == nil && yerr == nil && xn != yn:
+			if xn < yn {
+				return -1
+			}
+			return 1
+		case xerr != nil || yerr != nil:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
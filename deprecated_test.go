@@ -0,0 +1,48 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestDeprecated(t *testing.T) {
+	var conf struct {
+		Listen  string `config:"listen,optional" deprecated:"since=1.4,remove=2.0,use=addr"`
+		Addr    string `config:"addr,optional"`
+		Verbose bool   `config:"verbose,optional" deprecated:""`
+	}
+	input := "listen = :8080\nverbose = true"
+
+	var found []string
+	var report config.Report
+	err := config.Read("<input>", strings.NewReader(input), &conf, config.WithVersion("1.10"),
+		config.WithReport(&report), config.OnDeprecated(func(d config.Deprecation) {
+			found = append(found, d.String())
+		}), config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	expected := "option listen is deprecated since version 1.4 and will be removed in version 2.0, use addr instead"
+	if len(found) != 2 || found[0] != expected || found[1] != "option verbose is deprecated" {
+		t.Fatalf("expected 2 deprecations, found %v", found)
+	}
+
+	if len(report.Deprecated) != 2 || report.Deprecated[0].Source.String() != "file <input>" {
+		t.Fatalf("expected the deprecations to be reported, found %v", report.Deprecated)
+	}
+
+	err = config.Read("<input>", strings.NewReader(input), &conf, config.WithVersion("v2.0.1"),
+		config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "listen" || errs[0].Kind != config.KindRemoved {
+		t.Fatalf("expected listen to be removed, found %v", err)
+	}
+
+	if err := config.Read("<input>", strings.NewReader(input), &conf,
+		config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("expected deprecated options to be accepted without a version, found %v", err)
+	}
+}
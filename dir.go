@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DirFiles returns the paths of the config files in dir, in the order
+// [config.ReadDir] merges them: the files whose names end in `.conf`, leaving
+// out hidden files, sorted by name. Names starting with a number are sorted by
+// that number, so `9-base.conf` comes before `10-local.conf`, and come before
+// names which don't start with a number. Names with the same number are
+// sorted as text.
+func DirFiles(dir string) ([]string, error) {
+	return dirFiles(dir, &options{})
+}
+
+// dirFiles returns the paths of the config files in dir, from the file system
+// given to WithFS if any.
+func dirFiles(dir string, o *options) ([]string, error) {
+	var entries []fs.DirEntry
+	var err error
+	join := filepath.Join
+	if o.fsys != nil {
+		entries, err = fs.ReadDir(o.fsys, dir)
+		join = path.Join
+	} else {
+		entries, err = os.ReadDir(dir)
+	}
+	if err != nil {
+		return nil, &Error{File: dir, Kind: KindIO, Err: err}
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".conf") {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	slices.SortFunc(names, compareFileNames)
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = join(dir, name)
+	}
+	return paths, nil
+}
+
+// compareFileNames orders a and b as described by DirFiles.
+func compareFileNames(a, b string) int {
+	na, aok := numericPrefix(a)
+	nb, bok := numericPrefix(b)
+	switch {
+	case aok && !bok:
+		return -1
+	case !aok && bok:
+		return 1
+	case aok && bok && na != nb:
+		if na < nb {
+			return -1
+		}
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// numericPrefix returns the number name starts with, if it does.
+func numericPrefix(name string) (uint64, bool) {
+	end := strings.IndexFunc(name, func(c rune) bool {
+		return c < '0' || c > '9'
+	})
+	if end == -1 {
+		end = len(name)
+	}
+
+	n, err := strconv.ParseUint(name[:end], 10, 64)
+	return n, err == nil
+}
+
+// ReadDir reads the config files in dir into the struct obj points to, as
+// [config.ReadFiles] does, in the order given by [config.DirFiles]. With
+// [config.WithReport], the report records which file each option came from.
+// With [config.AllowMissingFile], a missing dir is treated as empty. Errors
+// which don't come from a particular file refer to dir.
+func ReadDir(dir string, obj any, opts ...Option) error {
+	o := newOptions(opts)
+	paths, err := dirFiles(dir, o)
+	if errors.Is(err, fs.ErrNotExist) && o.allowMissing {
+		paths = nil
+	} else if err != nil {
+		return o.finish(err)
+	}
+
+	return o.finish(read(dir, func() ([]parsedFile, error) {
+		return parseFiles(paths, o)
+	}, obj, o))
+}
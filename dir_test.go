@@ -0,0 +1,101 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"go.eldidi.org/config"
+)
+
+func TestReadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-local.conf":  "port = 8080",
+		"9-base.conf":    "port = 80\nhost = localhost",
+		"90-zz.conf":     "host = example.com",
+		"extra.conf":     "debug = true",
+		".hidden.conf":   "port = 1",
+		"README":         "not a config",
+		"10-admin.conf":  "admin = yes",
+		"010-other.conf": "other = yes",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := config.DirFiles(dir)
+	if err != nil {
+		t.Fatalf("failed to list dir: %v", err)
+	}
+
+	expected := []string{"9-base.conf", "010-other.conf", "10-admin.conf", "10-local.conf", "90-zz.conf", "extra.conf"}
+	for i, name := range expected {
+		expected[i] = filepath.Join(dir, name)
+	}
+	if !slices.Equal(paths, expected) {
+		t.Fatalf("expected %v, found %v", expected, paths)
+	}
+
+	var conf struct {
+		Host string
+		Port int
+	}
+	var report config.Report
+	if err := config.ReadDir(dir, &conf, config.WithReport(&report),
+		config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read dir into struct: %v", err)
+	}
+
+	if conf.Host != "example.com" || conf.Port != 8080 {
+		t.Fatalf("expected {example.com 8080}, found %+v", conf)
+	}
+
+	if report.Sources["port"].Name != filepath.Join(dir, "10-local.conf") {
+		t.Fatalf("expected port from 10-local.conf, found %v", report.Sources["port"])
+	}
+
+	missing := filepath.Join(dir, "missing")
+	err = config.ReadDir(missing, &conf, config.AllowMissingFile(), config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 2 || errs[0].File != missing || errs[0].Kind != config.KindMissing {
+		t.Fatalf("expected host and port to be missing from %v, found %v", missing, err)
+	}
+}
+
+func TestWithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/app.d/10-local.conf": {Data: []byte("port = 8080")},
+		"etc/app.d/9-base.conf":   {Data: []byte("port = 80\nhost = localhost")},
+		"etc/app.conf":            {Data: []byte("host = example.com")},
+	}
+
+	var conf struct {
+		Port int
+		Host string
+	}
+	if err := config.ReadDir("etc/app.d", &conf, config.WithFS(fsys),
+		config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+
+	if conf.Port != 8080 || conf.Host != "localhost" {
+		t.Fatalf("expected {8080 localhost}, found %+v", conf)
+	}
+
+	var app struct {
+		Host string
+	}
+	if err := config.ReadFile("etc/app.conf", &app, config.WithFS(fsys),
+		config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	if app.Host != "example.com" {
+		t.Fatalf(`expected "example.com", found "%v"`, app.Host)
+	}
+}
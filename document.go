@@ -0,0 +1,54 @@
+package config
+
+import (
+	"io"
+	"maps"
+	"slices"
+)
+
+// Document is a configuration file which has been parsed, and can be read
+// into any number of structs, or looked at again, without reading the file
+// again.
+type Document struct {
+	file parsedFile
+}
+
+// LoadDocument parses the configuration file at path from r into a Document.
+// Options which change how the file is parsed, such as [config.AllowNull] or
+// [config.WithReadLimit], apply when it's loaded, and are ignored when the
+// document is read into a struct.
+func LoadDocument(path string, r io.Reader, opts ...Option) (*Document, error) {
+	o := newOptions(opts)
+	f, err := parseNulls(path, r, o)
+	if err != nil {
+		return nil, o.finish(err)
+	}
+	return &Document{file: f}, nil
+}
+
+// Path returns the path the document was loaded from.
+func (d *Document) Path() string {
+	return d.file.path
+}
+
+// Values returns the options set in the document, as [config.Parse] would.
+func (d *Document) Values() Values {
+	return maps.Clone(d.file.vals)
+}
+
+// Entries returns each assignment in the document, as
+// [config.ParseEntries] would.
+func (d *Document) Entries() []Entry {
+	return slices.Clone(d.file.entries)
+}
+
+// Read reads the document into the struct obj points to, as [config.Read]
+// would have read the file. The environment is read again each time, so a
+// document can be read into several structs, or into the same struct again
+// after the environment changes.
+func (d *Document) Read(obj any, opts ...Option) error {
+	o := newOptions(opts)
+	return o.finish(read(d.file.path, func() ([]parsedFile, error) {
+		return []parsedFile{d.file}, nil
+	}, obj, o))
+}
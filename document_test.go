@@ -0,0 +1,59 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestLoadDocument(t *testing.T) {
+	doc, err := config.LoadDocument("<input>", strings.NewReader(`
+	# The address to listen on.
+	host = localhost
+	port = 8080
+	cache = null
+	`), config.AllowNull())
+	if err != nil {
+		t.Fatalf("failed to load document: %v", err)
+	}
+
+	vals := doc.Values()
+	if len(vals) != 2 || vals["host"] != "localhost" || vals["port"] != "8080" {
+		t.Fatalf("expected host and port, found %v", vals)
+	}
+
+	entries := doc.Entries()
+	if len(entries) != 3 || len(entries[0].Comments) != 1 || !entries[2].Null {
+		t.Fatalf("expected 3 entries, found %+v", entries)
+	}
+
+	var server struct {
+		Host string `config:"host"`
+		Port int    `config:"port"`
+	}
+	if err := doc.Read(&server, config.WithPrecedence(config.LayerFile)); err != nil {
+		t.Fatalf("failed to read document: %v", err)
+	}
+
+	if server.Host != "localhost" || server.Port != 8080 {
+		t.Fatalf("expected localhost:8080, found %+v", server)
+	}
+
+	t.Setenv("PORT", "9090")
+	var port struct {
+		Port int `config:"port"`
+	}
+	if err := doc.Read(&port); err != nil {
+		t.Fatalf("failed to read document: %v", err)
+	}
+
+	if port.Port != 9090 {
+		t.Fatalf("expected 9090, found %v", port.Port)
+	}
+
+	err = doc.Read(&port, config.DisallowUnknownKeys())
+	if errs := config.Errors(err); len(errs) != 1 || errs[0].Key != "host" || errs[0].File != "<input>" {
+		t.Fatalf("expected host to be unknown, found %v", err)
+	}
+}
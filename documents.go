@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ParseDocuments parses a stream holding several configuration files one
+// after the other, each separated from the next by a line holding only `---`,
+// and returns the values of each document in order. Line numbers in errors
+// count from the start of the stream. Documents without any assignments, such
+// as before a leading `---`, are left out.
+func ParseDocuments(path string, r io.Reader, opts ...Option) ([]Values, error) {
+	o := newOptions(opts)
+	docs, err := parseDocuments(path, r, o)
+	if err != nil {
+		return nil, o.finish(err)
+	}
+
+	vals := make([]Values, len(docs))
+	for i, doc := range docs {
+		vals[i] = doc.vals
+	}
+	return vals, nil
+}
+
+// ReadDocuments reads each of the documents in a stream, separated as
+// [config.ParseDocuments] describes, into its own element of the slice of
+// structs slice points to, as [config.Read] does. The slice is replaced with
+// one element per document. Errors which don't come from a particular line
+// refer to the document by number, as in `jobs.conf (document 2)`, and the
+// errors for every document are returned joined. With [config.WithReport],
+// the report describes the last document read.
+func ReadDocuments(path string, r io.Reader, slice any, opts ...Option) error {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice ||
+		v.Elem().Type().Elem().Kind() != reflect.Struct {
+		return ErrInvalid
+	}
+
+	o := newOptions(opts)
+	docs, err := parseDocuments(path, r, o)
+	if err != nil {
+		return o.finish(err)
+	}
+
+	elems := reflect.MakeSlice(v.Elem().Type(), len(docs), len(docs))
+	var errs []error
+	for i, doc := range docs {
+		err := read(doc.path, func() ([]parsedFile, error) {
+			return []parsedFile{doc}, nil
+		}, elems.Index(i).Addr().Interface(), o)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	v.Elem().Set(elems)
+	return o.finish(errors.Join(errs...))
+}
+
+// documentSeparator is the line which separates documents in a stream.
+const documentSeparator = "---"
+
+// parseDocuments parses each of the documents in the stream at path from r.
+// The path of each document includes its number.
+func parseDocuments(path string, r io.Reader, o *options) ([]parsedFile, error) {
+	var docs []parsedFile
+	var text strings.Builder
+	// start is the line the current document starts on.
+	start := 1
+	parseDocument := func() error {
+		docPath := fmt.Sprintf("%v (document %d)", path, len(docs)+1)
+		entries, err := parseEntries(docPath, strings.NewReader(text.String()), o)
+		if err != nil {
+			var e *Error
+			if errors.As(err, &e) && e.Line > 0 {
+				e.Line += start - 1
+			}
+			return err
+		}
+
+		if len(entries) > 0 {
+			for i := range entries {
+				entries[i].Line += start - 1
+			}
+			docs = append(docs, newParsedFile(docPath, entries))
+		}
+		text.Reset()
+		return nil
+	}
+
+	r, err := input(path, r, o)
+	if err != nil {
+		return nil, err
+	}
+
+	s := bufio.NewScanner(r)
+	for lineNo := 1; s.Scan(); lineNo += 1 {
+		if strings.TrimSpace(s.Text()) != documentSeparator {
+			text.WriteString(s.Text())
+			text.WriteByte('\n')
+			continue
+		}
+
+		if err := parseDocument(); err != nil {
+			return nil, err
+		}
+		start = lineNo + 1
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, &Error{File: path, Kind: KindIO, Err: err}
+	}
+
+	if err := parseDocument(); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestParseDocuments(t *testing.T) {
+	docs, err := config.ParseDocuments("<input>", strings.NewReader(`---
+name = build
+retries = 3
+---
+# cleanup runs after every build
+name = cleanup
+---
+`))
+	if err != nil {
+		t.Fatalf("failed to parse documents: %v", err)
+	}
+
+	if len(docs) != 2 || docs[0]["retries"] != "3" || docs[1]["name"] != "cleanup" {
+		t.Fatalf("expected 2 documents, found %v", docs)
+	}
+
+	_, err = config.ParseDocuments("<input>", strings.NewReader("a = 1\n---\nb = 2\n= 3"))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Line != 4 || errs[0].File != "<input> (document 2)" {
+		t.Fatalf("expected a syntax error on line 4 of document 2, found %v", err)
+	}
+}
+
+func TestReadDocuments(t *testing.T) {
+	type job struct {
+		Name    string
+		Retries int `config:"retries,optional"`
+	}
+
+	var jobs []job
+	err := config.ReadDocuments("<input>", strings.NewReader(`
+name = build
+retries = 3
+---
+name = cleanup
+`), &jobs, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to read documents: %v", err)
+	}
+
+	if len(jobs) != 2 || jobs[0] != (job{"build", 3}) || jobs[1] != (job{"cleanup", 0}) {
+		t.Fatalf("expected [{build 3} {cleanup 0}], found %+v", jobs)
+	}
+
+	err = config.ReadDocuments("<input>", strings.NewReader("retries = 1\n---\nname = x\n---\nretries = 2"), &jobs,
+		config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 2 || errs[0].File != "<input> (document 1)" || errs[1].File != "<input> (document 3)" {
+		t.Fatalf("expected documents 1 and 3 to be missing name, found %v", err)
+	}
+
+	if err := config.ReadDocuments("<input>", strings.NewReader(""), &job{}); err != config.ErrInvalid {
+		t.Fatalf("expected ErrInvalid, found %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	enumsMu sync.RWMutex
+	// enums maps a type to the names its values can be given as in a
+	// config file.
+	enums = map[reflect.Type]map[string]reflect.Value{}
+)
+
+// RegisterEnum declares the names which values of type T can be given as in a
+// config file. Once registered, a field of type T is set to the constant
+// matching its value's name, and any other value is an error listing the
+// allowed names. For example:
+//
+//	type LogFormat int
+//
+//	const (
+//		LogText LogFormat = iota
+//		LogJSON
+//	)
+//
+//	func init() {
+//		config.RegisterEnum(map[string]LogFormat{
+//			"text": LogText,
+//			"json": LogJSON,
+//		})
+//	}
+//
+// Registering the same type again replaces its names.
+func RegisterEnum[T any](names map[string]T) {
+	m := make(map[string]reflect.Value, len(names))
+	for name, val := range names {
+		m[name] = reflect.ValueOf(val)
+	}
+
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	enums[reflect.TypeFor[T]()] = m
+}
+
+// lookupEnum returns the names registered for typ, if there are any.
+func lookupEnum(typ reflect.Type) (map[string]reflect.Value, bool) {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+	m, ok := enums[typ]
+	return m, ok
+}
+
+// parseEnum returns the value named val in names.
+func parseEnum(names map[string]reflect.Value, val string) (reflect.Value, error) {
+	v, ok := names[val]
+	if !ok {
+		allowed := make([]string, 0, len(names))
+		for name := range names {
+			allowed = append(allowed, name)
+		}
+		sort.Strings(allowed)
+
+		return reflect.Value{}, fmt.Errorf(
+			"'%v' is not one of %v",
+			val, strings.Join(allowed, ", "),
+		)
+	}
+
+	return v, nil
+}
+
+// formatEnum returns the name of the value v in names.
+func formatEnum(names map[string]reflect.Value, v reflect.Value) (string, bool) {
+	for name, val := range names {
+		if val.Equal(v) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,47 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+type logFormat int
+
+const (
+	logText logFormat = iota
+	logJSON
+)
+
+func TestEnum(t *testing.T) {
+	config.RegisterEnum(map[string]logFormat{
+		"text": logText,
+		"json": logJSON,
+	})
+
+	var conf struct {
+		Format logFormat
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	format = json
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Format != logJSON {
+		t.Fatalf("expected %v, found %v", logJSON, conf.Format)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	format = xml
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	if !strings.Contains(err.Error(), "json, text") {
+		t.Fatalf("expected the allowed values to be listed, found: %v", err)
+	}
+}
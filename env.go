@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+)
+
+// EnvName returns the name of the environment variable for the option name:
+// the name in upper case, with anything other than letters, digits and
+// underscores replaced with underscores so the variable can be set from a
+// shell. If prefix isn't empty, it is added in front followed by an
+// underscore. This is the variable [config.Read] looks up for the option with
+// [config.WithEnvPrefix] given prefix.
+func EnvName(prefix, name string) string {
+	var b strings.Builder
+	if prefix != "" {
+		b.WriteString(strings.TrimSuffix(prefix, "_"))
+		b.WriteByte('_')
+	}
+
+	for _, c := range strings.ToUpper(name) {
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String()
+}
+
+// WithEnvPrefix makes the environment variable for each option start with
+// prefix followed by an underscore, so with the prefix `MYAPP` the option
+// `port` is overridden by `MYAPP_PORT`.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+	}
+}
+
+// Environment is the interface implemented by sources of environment
+// variables, for [config.WithEnvironment].
+type Environment interface {
+	LookupEnv(name string) (string, bool)
+}
+
+// EnvMap is an [Environment] holding the variables in a map, for platforms
+// such as js/wasm which have no environment of their own, and for tests.
+type EnvMap map[string]string
+
+func (m EnvMap) LookupEnv(name string) (string, bool) {
+	val, ok := m[name]
+	return val, ok
+}
+
+// osEnv is the environment of the process.
+type osEnv struct{}
+
+func (osEnv) LookupEnv(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+// WithEnvironment makes reading look up the environment variable for each
+// option in env, instead of in the environment of the process.
+func WithEnvironment(env Environment) Option {
+	return func(o *options) {
+		o.env = env
+	}
+}
+
+// ExportEnv returns the options in the struct pointed to by obj as `KEY=value`
+// environment variable assignments, named the way [config.Read] looks them
+// up with [config.WithEnvPrefix] given prefix. This is useful for handing the
+// effective configuration to a child process. Nil pointers are left out.
+func ExportEnv(obj any, prefix string) ([]string, error) {
+	return exportEnv(obj, prefix, true)
+}
+
+func exportEnv(obj any, prefix string, secrets bool) ([]string, error) {
+	v, err := structValue(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var env []string
+	for _, fi := range structFields(v) {
+		if fi.v.Kind() == reflect.Pointer && fi.v.IsNil() {
+			continue
+		}
+
+		if fi.secret && !secrets {
+			continue
+		}
+
+		val, err := formatValue(fi.v)
+		if err != nil {
+			return nil, fmt.Errorf(errorWritingConfig, fi.name, err)
+		}
+
+		env = append(env, EnvName(prefix, fi.name)+"="+val)
+	}
+
+	return env, nil
+}
+
+// ExportShell is like [config.ExportEnv], but returns the assignments as
+// `export KEY='value'` lines which can be sourced by a POSIX shell.
+func ExportShell(obj any, prefix string) (string, error) {
+	env, err := ExportEnv(obj, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, assignment := range env {
+		key, val, _ := strings.Cut(assignment, "=")
+		b.WriteString("export ")
+		b.WriteString(key)
+		b.WriteString("='")
+		b.WriteString(strings.ReplaceAll(val, "'", `'\''`))
+		b.WriteString("'\n")
+	}
+
+	return b.String(), nil
+}
+
+// SetCommandEnv adds the options in the struct pointed to by obj to the
+// environment of cmd, as [config.ExportEnv] would give them, so that a child
+// process reading the same struct with [config.WithEnvPrefix] given prefix
+// sees the same configuration. If cmd.Env is nil, the current process's
+// environment is added first so the child still inherits it.
+//
+// Options marked `secret` are left out: a child which needs them should be
+// given them explicitly.
+func SetCommandEnv(cmd *exec.Cmd, obj any, prefix string) error {
+	env, err := exportEnv(obj, prefix, false)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, env...)
+	return nil
+}
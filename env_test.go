@@ -0,0 +1,141 @@
+package config_test
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestEnvPrefix(t *testing.T) {
+	t.Setenv("MYAPP_PORT", "9090")
+	t.Setenv("MYAPP_BACKEND_PRIMARY", "10.0.0.1")
+
+	var conf struct {
+		Port    int
+		Primary string `config:"backend.primary"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	port = 8080
+	`), &conf, config.WithEnvPrefix("MYAPP"))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Port != 9090 {
+		t.Fatalf("expected 9090, found %v", conf.Port)
+	}
+
+	if conf.Primary != "10.0.0.1" {
+		t.Fatalf(`expected "10.0.0.1", found "%v"`, conf.Primary)
+	}
+}
+
+func TestExportEnv(t *testing.T) {
+	conf := struct {
+		Port     int
+		Greeting string
+		Primary  string `config:"backend.primary"`
+	}{8080, "it's me", "10.0.0.1"}
+
+	env, err := config.ExportEnv(&conf, "MYAPP")
+	if err != nil {
+		t.Fatalf("failed to export config: %v", err)
+	}
+
+	expected := []string{
+		"MYAPP_PORT=8080",
+		"MYAPP_GREETING=it's me",
+		"MYAPP_BACKEND_PRIMARY=10.0.0.1",
+	}
+	if !slices.Equal(env, expected) {
+		t.Fatalf("expected %q, found %q", expected, env)
+	}
+
+	shell, err := config.ExportShell(&conf, "MYAPP")
+	if err != nil {
+		t.Fatalf("failed to export config: %v", err)
+	}
+
+	if !strings.Contains(shell, `export MYAPP_GREETING='it'\''s me'`) {
+		t.Fatalf("expected the quote to be escaped, found:\n%v", shell)
+	}
+}
+
+func TestSetCommandEnv(t *testing.T) {
+	conf := struct {
+		Port     int
+		Password string `config:"password,secret"`
+	}{8080, "hunter2"}
+
+	cmd := exec.Command("true")
+	cmd.Env = []string{"PATH=/bin"}
+	if err := config.SetCommandEnv(cmd, &conf, "MYAPP"); err != nil {
+		t.Fatalf("failed to set command environment: %v", err)
+	}
+
+	expected := []string{"PATH=/bin", "MYAPP_PORT=8080"}
+	if !slices.Equal(cmd.Env, expected) {
+		t.Fatalf("expected %q, found %q", expected, cmd.Env)
+	}
+}
+
+func TestWithEnvironment(t *testing.T) {
+	t.Setenv("PORT", "1")
+
+	var conf struct {
+		Port int
+		Host string
+	}
+	env := config.EnvMap{"PORT": "9090", "HOST": "localhost"}
+	err := config.Read("<input>", strings.NewReader("port = 8080"), &conf, config.WithEnvironment(env))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Port != 9090 || conf.Host != "localhost" {
+		t.Fatalf("expected {9090 localhost}, found %+v", conf)
+	}
+}
+
+func TestEnvVars(t *testing.T) {
+	conf := struct {
+		Port     int    `config:"port,optional" comment:"Port to listen on"`
+		Host     string `config:"host,optional" comment:"Host name | address"`
+		Password string `config:"password,secret"`
+		Name     string
+		Schema   string `config:"schema,frozen"`
+	}{Port: 8080}
+
+	vars, err := config.EnvVars(&conf, "MYAPP")
+	if err != nil {
+		t.Fatalf("failed to list environment variables: %v", err)
+	}
+
+	var b strings.Builder
+	if err := config.WriteEnvMarkdown(&b, vars); err != nil {
+		t.Fatalf("failed to write Markdown: %v", err)
+	}
+
+	expected := "| Variable | Type | Default | Description |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `MYAPP_PORT` | `int` | `8080` | Port to listen on |\n" +
+		"| `MYAPP_HOST` | `string` | - | Host name \\| address |\n" +
+		"| `MYAPP_PASSWORD` | `string` | (secret) |  |\n" +
+		"| `MYAPP_NAME` | `string` | (required) |  |\n"
+	if b.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, b.String())
+	}
+
+	b.Reset()
+	if err := config.WriteEnvText(&b, vars[:1]); err != nil {
+		t.Fatalf("failed to write text: %v", err)
+	}
+
+	expected = "VARIABLE    TYPE  DEFAULT  DESCRIPTION\nMYAPP_PORT  int   8080     Port to listen on\n"
+	if b.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, b.String())
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// EnvVar describes the environment variable for an option, as returned by
+// [config.EnvVars].
+type EnvVar struct {
+	Name string
+	// Key is the name of the option in the config file.
+	Key      string
+	Type     reflect.Type
+	Optional bool
+	Secret   bool
+	// Default is the value the option has in the struct given to EnvVars,
+	// which it keeps when it isn't set. It is empty for required and secret
+	// options, and nil pointers.
+	Default string
+	// Description is the text of the field's `comment:""` struct tag.
+	Description string
+}
+
+// EnvVars returns the environment variables [config.Read] looks up for the
+// options in the struct obj points to, with [config.WithEnvPrefix] given
+// prefix, in the order of the struct's fields. Frozen options are left out,
+// since they can't be set from the environment. It's meant for generating
+// documentation, such as with [config.WriteEnvMarkdown], so it can't fall
+// out of date with the struct.
+func EnvVars(obj any, prefix string) ([]EnvVar, error) {
+	v, err := structValue(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]reflect.Value{}
+	for _, fi := range structFields(v) {
+		byName[fi.name] = fi.v
+	}
+
+	var vars []EnvVar
+	for _, fi := range typeFields(v.Type()) {
+		if fi.frozen {
+			continue
+		}
+
+		ev := EnvVar{
+			Name:        EnvName(prefix, fi.name),
+			Key:         fi.name,
+			Type:        fi.f.Type,
+			Optional:    fi.optional,
+			Secret:      fi.secret,
+			Description: fi.f.Tag.Get("comment"),
+		}
+		if field, ok := byName[fi.name]; ok && fi.optional && !fi.secret && !(field.Kind() == reflect.Pointer && field.IsNil()) {
+			if ev.Default, err = formatValue(field); err != nil {
+				return nil, fmt.Errorf(errorWritingConfig, fi.name, err)
+			}
+		}
+		vars = append(vars, ev)
+	}
+	return vars, nil
+}
+
+// WriteEnvText writes vars to w as an aligned table, for a `--help` message
+// or a terminal.
+func WriteEnvText(w io.Writer, vars []EnvVar) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIABLE\tTYPE\tDEFAULT\tDESCRIPTION")
+	for _, ev := range vars {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", ev.Name, ev.Type, envDefault(ev), ev.Description)
+	}
+	return tw.Flush()
+}
+
+// WriteEnvMarkdown writes vars to w as a Markdown table, for a README or the
+// documentation of a Helm chart.
+func WriteEnvMarkdown(w io.Writer, vars []EnvVar) error {
+	var b strings.Builder
+	b.WriteString("| Variable | Type | Default | Description |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, ev := range vars {
+		def := envDefault(ev)
+		if ev.Default != "" {
+			def = "`" + def + "`"
+		}
+		fmt.Fprintf(&b, "| `%v` | `%v` | %v | %v |\n", ev.Name, ev.Type,
+			markdownCell(def), markdownCell(ev.Description))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// envDefault returns the default of ev as it's shown in documentation.
+func envDefault(ev EnvVar) string {
+	switch {
+	case ev.Default != "":
+		return ev.Default
+	case ev.Secret:
+		return "(secret)"
+	case !ev.Optional:
+		return "(required)"
+	default:
+		return "-"
+	}
+}
+
+// markdownCell escapes text for a cell of a Markdown table.
+func markdownCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+)
+
+// Equal reports whether a and b, which must point to structs of the same
+// type, hold the same options. Fields tagged `config:"-"` aren't compared.
+// It returns false if a and b aren't pointers to structs of the same type.
+func Equal(a, b any) bool {
+	diffs, err := Differences(a, b)
+	return err == nil && len(diffs) == 0
+}
+
+// Differences returns a description of each option which differs between a
+// and b, which must point to structs of the same type, such as
+// `port: 8080 != 9090`. Secret options are compared, but their values are
+// never included. Fields tagged `config:"-"` aren't compared. This gives
+// more readable test failures than reflect.DeepEqual, without leaking
+// secrets into test output.
+func Differences(a, b any) ([]string, error) {
+	changes, err := Classify(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]string, len(changes))
+	for i, c := range changes {
+		diffs[i] = c.Description
+	}
+	return diffs, nil
+}
+
+// Change is an option which differs between two configs, as returned by
+// [config.Classify].
+type Change struct {
+	Key string
+	// Description describes the change as [config.Differences] does.
+	Description string
+	// Restart means the option is tagged `restart`, so the change only
+	// takes effect once the program restarts.
+	Restart bool
+}
+
+// Classify returns each option which differs between old and new, which must
+// point to structs of the same type, as [config.Differences] does, and whether
+// it needs a restart to take effect. When a config is read again while the
+// program is running, the changes which don't need a restart can be applied
+// straight away, and those which do, such as the address of a listener,
+// refused or deferred:
+//
+//	type Config struct {
+//		Addr     string `config:"addr,restart"`
+//		LogLevel string `config:"log_level"`
+//	}
+func Classify(old, new any) ([]Change, error) {
+	va, err := structValue(old)
+	if err != nil {
+		return nil, err
+	}
+
+	vb, err := structValue(new)
+	if err != nil {
+		return nil, err
+	}
+
+	if va.Type() != vb.Type() {
+		return nil, fmt.Errorf("config: can't compare a %v with a %v", va.Type(), vb.Type())
+	}
+
+	var changes []Change
+	for _, fi := range typeFields(va.Type()) {
+		c := Change{Key: fi.name, Restart: fi.restart}
+		fa, erra := va.FieldByIndexErr(fi.index)
+		fb, errb := vb.FieldByIndexErr(fi.index)
+		switch {
+		case erra != nil && errb != nil:
+			continue
+		case erra != nil || errb != nil:
+			c.Description = fmt.Sprintf("%v: only set in one", fi.name)
+		case equalValues(fa, fb):
+			continue
+		case fi.secret:
+			c.Description = fmt.Sprintf("%v: secret values differ", fi.name)
+		default:
+			c.Description = fmt.Sprintf("%v: %v != %v", fi.name, describe(fa), describe(fb))
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, nil
+}
+
+// equalValues reports whether a and b hold the same value.
+func equalValues(a, b reflect.Value) bool {
+	if a.CanInterface() {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+
+	// Unexported fields set through a FieldSetter can't be turned back
+	// into interfaces, but their contents can still be hashed.
+	ha, hb := fnv.New64a(), fnv.New64a()
+	hashValue(ha, a, map[uintptr]bool{})
+	hashValue(hb, b, map[uintptr]bool{})
+	return ha.Sum64() == hb.Sum64()
+}
+
+// describe returns the text form of v, for showing it in a message.
+func describe(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return "<nil>"
+	} else if !v.CanInterface() {
+		return "<unexported>"
+	}
+
+	if val, err := formatValue(v); err == nil {
+		return fmt.Sprintf("%q", val)
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
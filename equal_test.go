@@ -0,0 +1,69 @@
+package config_test
+
+import (
+	"slices"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+type equalConfig struct {
+	Host  string
+	Port  int
+	Token string            `config:"token,secret"`
+	Cache map[string]string `config:"-"`
+}
+
+func TestEqual(t *testing.T) {
+	a := equalConfig{"localhost", 8080, "hunter2", map[string]string{"a": "b"}}
+	b := equalConfig{"localhost", 8080, "hunter2", nil}
+	if !config.Equal(&a, &b) {
+		t.Fatal("expected configs differing only in an ignored field to be equal")
+	}
+
+	b.Port = 9090
+	b.Token = "letmein"
+	diffs, err := config.Differences(&a, &b)
+	if err != nil {
+		t.Fatalf("failed to compare configs: %v", err)
+	}
+
+	expected := []string{`port: "8080" != "9090"`, "token: secret values differ"}
+	if !slices.Equal(diffs, expected) {
+		t.Fatalf("expected %q, found %q", expected, diffs)
+	}
+
+	if config.Equal(&a, &struct{}{}) {
+		t.Fatal("expected configs of different types not to be equal")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	type reloadConfig struct {
+		Addr     string `config:"addr,restart"`
+		LogLevel string `config:"log_level"`
+		Server   struct {
+			Workers int
+		} `config:"server,restart"`
+	}
+
+	old := reloadConfig{Addr: ":8080", LogLevel: "info"}
+	new := old
+	new.Addr = ":9090"
+	new.LogLevel = "debug"
+	new.Server.Workers = 4
+
+	changes, err := config.Classify(&old, &new)
+	if err != nil {
+		t.Fatalf("failed to classify changes: %v", err)
+	}
+
+	expected := []config.Change{
+		{Key: "addr", Description: `addr: ":8080" != ":9090"`, Restart: true},
+		{Key: "log_level", Description: `log_level: "info" != "debug"`},
+		{Key: "server.workers", Description: `server.workers: "0" != "4"`, Restart: true},
+	}
+	if !slices.Equal(changes, expected) {
+		t.Fatalf("expected %v, found %v", expected, changes)
+	}
+}
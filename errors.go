@@ -0,0 +1,137 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrorKind classifies the problems reported by an [Error].
+type ErrorKind string
+
+const (
+	// KindIO means the file couldn't be opened or read.
+	KindIO ErrorKind = "io"
+	// KindSyntax means a line of the file couldn't be parsed.
+	KindSyntax ErrorKind = "syntax"
+	// KindMissing means a required option wasn't given.
+	KindMissing ErrorKind = "missing"
+	// KindInvalid means an option's value couldn't be converted to the
+	// field's type or failed validation.
+	KindInvalid ErrorKind = "invalid"
+	// KindConstraint means a constraint between several options, such as
+	// a group, wasn't satisfied.
+	KindConstraint ErrorKind = "constraint"
+	// KindUnsupported means the struct asks for something the package
+	// can't do, such as reading a field of a type it doesn't know.
+	KindUnsupported ErrorKind = "unsupported"
+	// KindUnknown means the file sets an option the struct doesn't have,
+	// reported with [config.DisallowUnknownKeys].
+	KindUnknown ErrorKind = "unknown"
+	// KindPolicy means an option's value was rejected by a [config.Policy].
+	KindPolicy ErrorKind = "policy"
+	// KindRemoved means a deprecated option was set in a version at or
+	// after the one it was removed in, given with [config.WithVersion].
+	KindRemoved ErrorKind = "removed"
+	// KindSignature means a file's signature was missing or didn't match,
+	// with [config.VerifySignatures].
+	KindSignature ErrorKind = "signature"
+)
+
+// Error describes a problem found while reading a configuration. Every error
+// returned by [config.Parse] and [config.Read] is an *Error, or several of
+// them joined with errors.Join, except for [config.ErrInvalid].
+type Error struct {
+	// File is the path given to Parse or Read.
+	File string
+	// Line is the line the problem was found on, or 0 if it isn't tied to
+	// a particular line.
+	Line int
+	// Key is the option the problem concerns, or the group name for
+	// KindConstraint. It is empty for syntax errors.
+	Key  string
+	Kind ErrorKind
+	Err  error
+
+	// format produces the message instead of the default format, if set.
+	format func(*Error) string
+}
+
+func newError(path, key string, kind ErrorKind, err error) *Error {
+	return &Error{File: path, Key: key, Kind: kind, Err: err}
+}
+
+func invalidError(path, key string, err error) *Error {
+	return newError(path, key, KindInvalid,
+		fmt.Errorf("invalid value for %v: %w", key, err))
+}
+
+func (e *Error) Error() string {
+	if e.format != nil {
+		return e.format(e)
+	}
+
+	if e.Line > 0 {
+		return fmt.Sprintf("error:%v:%v: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("error parsing config '%v': %v", e.File, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is makes syntax errors match [config.ErrSyntax].
+func (e *Error) Is(target error) bool {
+	return target == ErrSyntax && e.Kind == KindSyntax
+}
+
+// Errors returns each *Error contained in err, which may have been joined
+// with others. Errors which aren't an *Error are returned as one with only
+// Err set.
+func Errors(err error) []*Error {
+	if err == nil {
+		return nil
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []*Error
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, Errors(err)...)
+		}
+		return errs
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return []*Error{e}
+	}
+	return []*Error{{Err: err}}
+}
+
+type jsonError struct {
+	File    string    `json:"file"`
+	Line    int       `json:"line"`
+	Key     string    `json:"key"`
+	Kind    ErrorKind `json:"kind"`
+	Message string    `json:"message"`
+}
+
+// FormatJSON renders the errors contained in err as a JSON array of objects
+// with the fields `file`, `line`, `key`, `kind` and `message`, for tools which
+// need to consume them. A nil error is rendered as an empty array.
+func FormatJSON(err error) ([]byte, error) {
+	errs := Errors(err)
+	out := make([]jsonError, len(errs))
+	for i, e := range errs {
+		out[i] = jsonError{
+			File:    e.File,
+			Line:    e.Line,
+			Key:     e.Key,
+			Kind:    e.Kind,
+			Message: e.Err.Error(),
+		}
+	}
+
+	return json.Marshal(out)
+}
@@ -0,0 +1,167 @@
+package config_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestSyntaxError(t *testing.T) {
+	_, err := config.Parse("app.conf", strings.NewReader(`
+	port = 8080
+	= 1
+	`))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	if !errors.Is(err, config.ErrSyntax) {
+		t.Fatalf("expected a syntax error, found: %v", err)
+	}
+
+	var e *config.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected a *config.Error, found %T", err)
+	}
+
+	if e.File != "app.conf" || e.Line != 3 {
+		t.Fatalf("expected app.conf:3, found %v:%v", e.File, e.Line)
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	var conf struct {
+		Port    int
+		A       string `group:"listener,exactlyone"`
+		B       string `group:"listener"`
+		Timeout int
+	}
+	err := config.Read("app.conf", strings.NewReader(`
+	port = eighty
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	out, err := config.FormatJSON(err)
+	if err != nil {
+		t.Fatalf("failed to format errors: %v", err)
+	}
+
+	var errs []struct {
+		File    string `json:"file"`
+		Line    int    `json:"line"`
+		Key     string `json:"key"`
+		Kind    string `json:"kind"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(out, &errs); err != nil {
+		t.Fatalf("failed to decode %s: %v", out, err)
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, found %v: %s", len(errs), out)
+	}
+
+	if errs[0].File != "app.conf" || errs[0].Key != "port" ||
+		errs[0].Kind != "invalid" || errs[0].Message == "" {
+		t.Fatalf("unexpected error object: %s", out)
+	}
+
+	err = config.Read("app.conf", strings.NewReader(`
+	port = 80
+	timeout = 5
+	a = 1
+	b = 2
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	out, _ = config.FormatJSON(err)
+	if err := json.Unmarshal(out, &errs); err != nil {
+		t.Fatalf("failed to decode %s: %v", out, err)
+	}
+
+	if len(errs) != 1 || errs[0].Kind != "constraint" || errs[0].Key != "listener" {
+		t.Fatalf("unexpected error objects: %s", out)
+	}
+}
+
+func TestErrorFormatter(t *testing.T) {
+	var conf struct {
+		Port int
+	}
+	err := config.Read("app.conf", strings.NewReader(`
+	port = eighty
+	`), &conf, config.WithErrorFormatter(func(e *config.Error) string {
+		return e.Key + " must be a number, check " + e.File
+	}), config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	if err.Error() != "port must be a number, check app.conf" {
+		t.Fatalf(`expected "port must be a number, check app.conf", found "%v"`, err)
+	}
+}
+
+func TestStrictKeys(t *testing.T) {
+	_, err := config.Parse("app.conf", strings.NewReader(`
+	db.primary.host = localhost
+	tls_cert-file = cert.pem
+	`), config.StrictKeys())
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	_, err = config.Parse("app.conf", strings.NewReader(`
+	port = 8080
+	foo bar = 1
+	`), config.StrictKeys())
+	if !errors.Is(err, config.ErrSyntax) {
+		t.Fatalf("expected a syntax error, found: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "app.conf:3") {
+		t.Fatalf("expected the error to give the line, found: %v", err)
+	}
+}
+
+func TestDisallowUnknownKeys(t *testing.T) {
+	var conf struct {
+		Port int `config:"port"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	port = 8080
+	prot = 8081
+	x-editor.theme = dark
+	`), &conf, config.DisallowUnknownKeys(), config.IgnoreKeys("x-*"),
+		config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "prot" || errs[0].Kind != config.KindUnknown {
+		t.Fatalf("expected prot to be unknown, found %v", err)
+	}
+
+	vals := config.Values{"metrics.port": "9100", "server.port": "8080"}
+	err = config.ReadSection(vals, "metrics", &conf, config.DisallowUnknownKeys(),
+		config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to read section: %v", err)
+	}
+}
+
+func TestWithAllowedUnknown(t *testing.T) {
+	var conf struct {
+		Port int `config:"port"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	port = 8080
+	x_editor = vim
+	experimental_http3 = true
+	experimantal_quic = true
+	`), &conf, config.DisallowUnknownKeys(), config.WithAllowedUnknown("x_", "experimental_"))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "experimantal_quic" || errs[0].Kind != config.KindUnknown {
+		t.Fatalf("expected experimantal_quic to be unknown, found %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AllowExpressions makes a value containing `${` an arithmetic expression,
+// evaluated when reading into a struct, so related options can be derived
+// from each other, as in `read_timeout = ${base_timeout} * 2`. Expressions
+// are made of numbers, durations in the form accepted by time.ParseDuration,
+// references to other options like `${base_timeout}`, `+`, `-`, `*`, `/` and
+// parentheses. Durations can be added to and subtracted from each other, and
+// multiplied or divided by numbers. References use the effective value of the
+// option, after the environment and overrides are applied, and may be
+// expressions themselves. The result is written as a plain number or as a
+// duration like `1m30s`. The values of options tagged `raw` are never
+// evaluated.
+func AllowExpressions() Option {
+	return func(o *options) {
+		o.expressions = true
+	}
+}
+
+// evaluate replaces each of the values in s.vals which is an expression with
+// its result. The values of raw fields are left as they are.
+func (s *readState) evaluate(fields []fieldInfo) error {
+	raw := map[string]bool{}
+	for _, fi := range fields {
+		if fi.raw {
+			raw[fi.name] = true
+		}
+	}
+
+	e := evaluator{vals: s.vals, raw: raw, results: map[string]exprValue{}, visiting: map[string]bool{}}
+	var keys []string
+	for key, val := range s.vals {
+		if strings.Contains(val, "${") && !raw[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	results := map[string]string{}
+	for _, key := range keys {
+		result, err := e.option(key)
+		if err != nil {
+			errs = append(errs, invalidError(s.path, key, err))
+			continue
+		}
+		results[key] = result.String()
+	}
+
+	for key, val := range results {
+		s.vals[key] = val
+	}
+	return errors.Join(errs...)
+}
+
+// exprValue is the value of an expression, a number or a duration in
+// nanoseconds.
+type exprValue struct {
+	n        float64
+	duration bool
+}
+
+func (v exprValue) String() string {
+	if v.duration {
+		return time.Duration(math.Round(v.n)).String()
+	}
+	return strconv.FormatFloat(v.n, 'f', -1, 64)
+}
+
+// evaluator evaluates the expressions in vals.
+type evaluator struct {
+	vals Values
+	// raw holds the options whose values are never expressions.
+	raw map[string]bool
+	// results holds the value of each option evaluated so far.
+	results map[string]exprValue
+	// visiting holds the options being evaluated, to find cycles.
+	visiting map[string]bool
+}
+
+// option returns the value of the option key.
+func (e *evaluator) option(key string) (exprValue, error) {
+	if v, ok := e.results[key]; ok {
+		return v, nil
+	}
+
+	val, ok := e.vals[key]
+	if !ok {
+		return exprValue{}, fmt.Errorf("%v isn't set", key)
+	}
+
+	if e.raw[key] {
+		return literal(strings.TrimSpace(val))
+	}
+
+	if e.visiting[key] {
+		return exprValue{}, fmt.Errorf("%v refers to itself", key)
+	}
+	e.visiting[key] = true
+	defer delete(e.visiting, key)
+
+	p := exprParser{s: val, e: e}
+	v, err := p.expr()
+	if err == nil && p.skipSpace() < len(p.s) {
+		err = fmt.Errorf("unexpected '%v' in '%v'", p.s[p.pos:], val)
+	}
+	if err != nil {
+		return exprValue{}, err
+	}
+
+	e.results[key] = v
+	return v, nil
+}
+
+// exprParser evaluates the expression s while parsing it.
+type exprParser struct {
+	s   string
+	pos int
+	e   *evaluator
+}
+
+// skipSpace skips whitespace, returning the position after it.
+func (p *exprParser) skipSpace() int {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos += 1
+	}
+	return p.pos
+}
+
+// next returns the next character, or 0 at the end of the expression.
+func (p *exprParser) next() byte {
+	if p.skipSpace() == len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// expr parses a sum of terms.
+func (p *exprParser) expr() (exprValue, error) {
+	v, err := p.term()
+	for err == nil {
+		op := p.next()
+		if op != '+' && op != '-' {
+			break
+		}
+
+		p.pos += 1
+		var rhs exprValue
+		if rhs, err = p.term(); err == nil {
+			v, err = apply(op, v, rhs)
+		}
+	}
+	return v, err
+}
+
+// term parses a product of factors.
+func (p *exprParser) term() (exprValue, error) {
+	v, err := p.factor()
+	for err == nil {
+		op := p.next()
+		if op != '*' && op != '/' {
+			break
+		}
+
+		p.pos += 1
+		var rhs exprValue
+		if rhs, err = p.factor(); err == nil {
+			v, err = apply(op, v, rhs)
+		}
+	}
+	return v, err
+}
+
+// factor parses a literal, a reference, a negation or an expression in
+// parentheses.
+func (p *exprParser) factor() (exprValue, error) {
+	switch p.next() {
+	case 0:
+		return exprValue{}, fmt.Errorf("unexpected end of '%v'", p.s)
+	case '-':
+		p.pos += 1
+		v, err := p.factor()
+		v.n = -v.n
+		return v, err
+	case '(':
+		p.pos += 1
+		v, err := p.expr()
+		if err == nil && p.next() != ')' {
+			return exprValue{}, fmt.Errorf("missing ')' in '%v'", p.s)
+		}
+		p.pos += 1
+		return v, err
+	case '$':
+		rest, ok := strings.CutPrefix(p.s[p.pos:], "${")
+		end := strings.IndexByte(rest, '}')
+		if !ok || end == -1 {
+			return exprValue{}, fmt.Errorf("unterminated reference in '%v'", p.s)
+		}
+
+		p.pos += len("${") + end + len("}")
+		return p.e.option(strings.TrimSpace(rest[:end]))
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] == '.' || isLiteralRune(rune(p.s[p.pos]))) {
+		p.pos += 1
+	}
+	// Durations may use µs.
+	for strings.HasPrefix(p.s[p.pos:], "µ") {
+		p.pos += len("µ")
+		for p.pos < len(p.s) && isLiteralRune(rune(p.s[p.pos])) {
+			p.pos += 1
+		}
+	}
+	return literal(p.s[start:p.pos])
+}
+
+// isLiteralRune reports whether c can be part of a number or duration.
+func isLiteralRune(c rune) bool {
+	return c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c))
+}
+
+// literal returns the value of the number or duration s.
+func literal(s string) (exprValue, error) {
+	if s == "" {
+		return exprValue{}, errors.New("expected a number or duration")
+	}
+
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return exprValue{n: n}, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return exprValue{}, fmt.Errorf("'%v' isn't a number or duration", s)
+	}
+	return exprValue{n: float64(d), duration: true}, nil
+}
+
+// apply returns the result of the operator op applied to a and b.
+func apply(op byte, a, b exprValue) (exprValue, error) {
+	switch op {
+	case '+', '-':
+		if a.duration != b.duration {
+			return exprValue{}, fmt.Errorf("can't use '%c' between a number and a duration", op)
+		}
+		if op == '-' {
+			b.n = -b.n
+		}
+		return exprValue{n: a.n + b.n, duration: a.duration}, nil
+	case '*':
+		if a.duration && b.duration {
+			return exprValue{}, errors.New("can't multiply two durations")
+		}
+		return exprValue{n: a.n * b.n, duration: a.duration || b.duration}, nil
+	default:
+		if !a.duration && b.duration {
+			return exprValue{}, errors.New("can't divide a number by a duration")
+		}
+		if b.n == 0 {
+			return exprValue{}, errors.New("division by zero")
+		}
+		return exprValue{n: a.n / b.n, duration: a.duration && !b.duration}, nil
+	}
+}
@@ -0,0 +1,107 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestAllowExpressions(t *testing.T) {
+	env := config.EnvMap{"EXPR_BASE_TIMEOUT": "10s"}
+
+	var conf struct {
+		BaseTimeout  string  `config:"expr_base_timeout"`
+		ReadTimeout  string  `config:"expr_read_timeout"`
+		WriteTimeout string  `config:"expr_write_timeout"`
+		Workers      int     `config:"expr_workers"`
+		Ratio        float64 `config:"expr_ratio"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	expr_base_timeout = 5s
+	expr_read_timeout = ${expr_base_timeout} * 2
+	expr_write_timeout = (${expr_read_timeout} + 500ms) / 2
+	expr_workers = ${expr_cpus} * 4 - 1
+	expr_cpus = 2
+	expr_ratio = ${expr_read_timeout} / ${expr_base_timeout}
+	`), &conf, config.AllowExpressions(), config.WithEnvironment(env))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	if conf.ReadTimeout != "20s" || conf.WriteTimeout != "10.25s" {
+		t.Fatalf("expected 20s and 10.25s, found %v and %v", conf.ReadTimeout, conf.WriteTimeout)
+	}
+
+	if conf.Workers != 7 || conf.Ratio != 2 {
+		t.Fatalf("expected 7 and 2, found %v and %v", conf.Workers, conf.Ratio)
+	}
+}
+
+func TestAllowExpressionsErrors(t *testing.T) {
+	var conf struct {
+		A int `config:"a,optional"`
+		B int `config:"b,optional"`
+		C int `config:"c,optional"`
+		D int `config:"d,optional"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	a = ${b} + 1
+	b = ${a} + 1
+	c = ${e} + 1
+	e = 1s
+	d = ${missing} / 0
+	`), &conf, config.AllowExpressions(), config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, found %v: %v", len(errs), err)
+	}
+
+	for i, key := range []string{"a", "b", "c", "d"} {
+		if errs[i].Key != key || errs[i].Kind != config.KindInvalid {
+			t.Fatalf("expected %v to be invalid, found %v", key, errs[i])
+		}
+	}
+
+	if !strings.Contains(errs[0].Error(), "refers to itself") {
+		t.Fatalf("expected a cycle to be reported, found %v", errs[0])
+	}
+}
+
+type upperString string
+
+func (s *upperString) ParseConfigValue(val string) error {
+	*s = upperString(strings.ToUpper(val))
+	return nil
+}
+
+func TestRawOptions(t *testing.T) {
+	var conf struct {
+		Template string      `config:"template,raw"`
+		Name     upperString `config:"name,raw"`
+		Timeout  string      `config:"timeout"`
+		Base     string      `config:"base,raw"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	template = "Hello ${user} # 1"
+	name = app
+	base = 5s
+	timeout = ${base} * 2
+	`), &conf, config.AllowExpressions(), config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	if conf.Template != "Hello ${user} # 1" || conf.Name != "app" || conf.Timeout != "10s" {
+		t.Fatalf("expected the raw values as given, found %+v", conf)
+	}
+
+	var bad struct {
+		Port int `config:"port,raw,optional"`
+	}
+	err = config.Read("<input>", strings.NewReader(""), &bad, config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "port" || errs[0].Kind != config.KindUnsupported {
+		t.Fatalf("expected a raw int to be unsupported, found %v", err)
+	}
+}
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Field describes an option of a struct, as returned by [config.Fields].
+type Field struct {
+	// Name is the name of the option in the config file.
+	Name     string
+	Type     reflect.Type
+	Optional bool
+	Frozen   bool
+	Secret   bool
+	Restart  bool
+	// Tag is the struct tag of the field, for reading tags such as
+	// `comment:""`.
+	Tag reflect.StructTag
+}
+
+// Fields returns the options of the struct obj points to, in the order Read
+// reads them, including those of embedded structs.
+func Fields(obj any) ([]Field, error) {
+	v, err := structValue(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return exportFields(typeFields(v.Type())), nil
+}
+
+// exportFields returns the Field for each of plan.
+func exportFields(plan []fieldInfo) []Field {
+	fields := make([]Field, len(plan))
+	for i, fi := range plan {
+		fields[i] = Field{
+			Name:     fi.name,
+			Type:     fi.f.Type,
+			Optional: fi.optional,
+			Frozen:   fi.frozen,
+			Secret:   fi.secret,
+			Restart:  fi.restart,
+			Tag:      fi.f.Tag,
+		}
+	}
+	return fields
+}
+
+// fieldInfo describes a struct field which holds an option.
+type fieldInfo struct {
+	// name is the name of the option in the config file.
+	name string
+	// parent is the name of the struct holding the option, which the
+	// options named in its requiredif tag are relative to.
+	parent   string
+	optional bool
+	// frozen means the option can only be set from the file.
+	frozen bool
+	// secret means the option holds a credential which shouldn't be
+	// passed on or shown.
+	secret bool
+	// restart means a change to the option only takes effect once the
+	// program restarts, such as a listener's address.
+	restart bool
+	// raw means the value is set as it is, without any conversion or
+	// expressions.
+	raw bool
+	// prefix replaces name as the prefix of the options of a nested
+	// struct, if hasPrefix is set. An empty prefix inlines them.
+	prefix    string
+	hasPrefix bool
+	f         reflect.StructField
+	// index is the index sequence of the field for FieldByIndex.
+	index []int
+	// blocks holds the length of each prefix of index which leads to a
+	// pointer to a nested struct. The struct is only allocated when one of
+	// its options is set.
+	blocks []int
+	// setter means the field is unexported, and is set through the
+	// FieldSetter of the struct holding it, by the name local.
+	setter bool
+	local  string
+	// cycle means the field holds a struct it is nested in, so its options
+	// would go on forever.
+	cycle bool
+	// v is the field's value, when the fields of a particular struct
+	// value are needed. For setter fields, it is the struct holding the
+	// field instead.
+	v reflect.Value
+}
+
+// plans caches the result of typeFields for each struct type.
+var plans sync.Map
+
+// typeFields returns the fields of the struct type t which hold options. The
+// fields of embedded structs are included as if they were fields of t itself,
+// and those of nested structs are included with the name of the nested struct
+// and a dot before their own. The result is cached, and must not be modified.
+func typeFields(t reflect.Type) []fieldInfo {
+	if fields, ok := plans.Load(t); ok {
+		return fields.([]fieldInfo)
+	}
+
+	fields, _ := plans.LoadOrStore(t, collectFields(t, fieldInfo{}, nil))
+	return fields.([]fieldInfo)
+}
+
+// sectionKey is the key in plans for the fields of a struct type read from
+// a section.
+type sectionKey struct {
+	t      reflect.Type
+	prefix string
+}
+
+// sectionFields returns the fields of the struct type t as if it were nested
+// under prefix. The result is cached, and must not be modified.
+func sectionFields(t reflect.Type, prefix string) []fieldInfo {
+	if prefix == "" {
+		return typeFields(t)
+	}
+
+	key := sectionKey{t, prefix}
+	if fields, ok := plans.Load(key); ok {
+		return fields.([]fieldInfo)
+	}
+
+	fields, _ := plans.LoadOrStore(key, collectFields(t, fieldInfo{name: prefix}, nil))
+	return fields.([]fieldInfo)
+}
+
+// collectFields returns the fields of the struct type t, which is reached
+// through parent. The names of the fields are prefixed with the name of
+// parent, and they inherit its options. path holds the struct types t is
+// nested in, so that a struct nested in itself isn't followed forever.
+func collectFields(t reflect.Type, parent fieldInfo, path []reflect.Type) []fieldInfo {
+	path = append(path[:len(path):len(path)], t)
+	var fields []fieldInfo
+	numFields := t.NumField()
+	for i := 0; i < numFields; i += 1 {
+		f := t.Field(i)
+		index := append(parent.index[:len(parent.index):len(parent.index)], i)
+		if isEmbedded(f) {
+			embedded := parent
+			embedded.index = index
+			fields = append(fields, collectFields(f.Type, embedded, path)...)
+			continue
+		}
+
+		if f.Tag.Get("config") == "-" {
+			continue
+		}
+
+		setter := false
+		if !f.IsExported() {
+			if _, ok := f.Tag.Lookup("config"); !ok || !reflect.PointerTo(t).Implements(fieldSetterType) {
+				continue
+			}
+			setter = true
+		}
+
+		fi := parseTag(f)
+		fi.setter = setter
+		fi.local = fi.name
+		fi.name = joinName(parent.name, fi.name)
+		fi.parent = parent.name
+		fi.optional = fi.optional || parent.optional
+		fi.frozen = fi.frozen || parent.frozen
+		fi.secret = fi.secret || parent.secret
+		fi.restart = fi.restart || parent.restart
+		fi.f = f
+		fi.index = index
+		fi.blocks = parent.blocks
+
+		if t, ok := nestedStruct(f.Type); ok && !setter {
+			if slices.Contains(path, t) {
+				// The field can't be read, which readFields reports.
+				fi.cycle = true
+				fields = append(fields, fi)
+				continue
+			}
+
+			if fi.hasPrefix {
+				fi.name = joinName(parent.name, fi.prefix)
+			}
+			if f.Type.Kind() == reflect.Pointer {
+				fi.blocks = append(fi.blocks[:len(fi.blocks):len(fi.blocks)], len(index))
+			}
+			fields = append(fields, collectFields(t, fi, path)...)
+			continue
+		}
+		fields = append(fields, fi)
+	}
+
+	return fields
+}
+
+// joinName returns the name of the option name nested in prefix.
+func joinName(prefix, name string) string {
+	switch {
+	case prefix == "":
+		return name
+	case name == "":
+		return prefix
+	default:
+		return prefix + "." + name
+	}
+}
+
+// nestedStruct returns the struct type of the options nested in a field of
+// type t, if t is a struct, or a pointer to one, which isn't a value itself.
+func nestedStruct(t reflect.Type) (reflect.Type, bool) {
+	if hasConversion(t) {
+		return nil, false
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || hasParser(t) {
+		return nil, false
+	}
+	return t, true
+}
+
+// SkippedField is a struct field which isn't read because it is unexported,
+// as listed in [Report].
+type SkippedField struct {
+	// Field is the path to the field from the struct being read, such as
+	// `Server.port`.
+	Field  string
+	Reason string
+}
+
+// skippedFields returns the fields of the struct type t, and of the structs
+// nested in it, which aren't read because they're unexported. path is the
+// path to t from the struct being read, and types the struct types along it.
+func skippedFields(t reflect.Type, path string, types []reflect.Type) []SkippedField {
+	types = append(types[:len(types):len(types)], t)
+	var skipped []SkippedField
+	numFields := t.NumField()
+	for i := 0; i < numFields; i += 1 {
+		f := t.Field(i)
+		fieldPath := joinName(path, f.Name)
+		if isEmbedded(f) {
+			skipped = append(skipped, skippedFields(f.Type, fieldPath, types)...)
+			continue
+		}
+
+		_, tagged := f.Tag.Lookup("config")
+		setter := reflect.PointerTo(t).Implements(fieldSetterType)
+		switch {
+		case f.IsExported():
+			if nested, ok := nestedStruct(f.Type); ok && !slices.Contains(types, nested) {
+				skipped = append(skipped, skippedFields(nested, fieldPath, types)...)
+			}
+		case tagged && !setter:
+			skipped = append(skipped, SkippedField{
+				Field:  fieldPath,
+				Reason: fmt.Sprintf("unexported field has a config tag, but *%v doesn't implement config.FieldSetter", t),
+			})
+		case !tagged:
+			skipped = append(skipped, SkippedField{
+				Field:  fieldPath,
+				Reason: "unexported field",
+			})
+		}
+	}
+	return skipped
+}
+
+// structFields returns the fields of the struct v which hold options, with
+// their values filled in. Fields of nested structs behind nil pointers are
+// left out, as are unexported fields set through a FieldSetter, whose values
+// can't be read.
+func structFields(v reflect.Value) []fieldInfo {
+	plan := typeFields(v.Type())
+	fields := make([]fieldInfo, 0, len(plan))
+	for _, fi := range plan {
+		field, err := v.FieldByIndexErr(fi.index)
+		if err != nil || fi.setter {
+			continue
+		}
+
+		fi.v = field
+		fields = append(fields, fi)
+	}
+
+	return fields
+}
+
+// structValue returns the struct obj points to, or ErrInvalid if obj isn't a
+// non-nil pointer to a struct.
+func structValue(obj any) (reflect.Value, error) {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return reflect.Value{}, ErrInvalid
+	}
+
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, ErrInvalid
+	}
+	return v, nil
+}
+
+// parseTag returns the options given in the `config:""` struct tag of f.
+// Without a name in the tag, the field's name is converted to snake_case.
+func parseTag(f reflect.StructField) fieldInfo {
+	fi := fieldInfo{name: toSnakeCase(f.Name)}
+	if tag := f.Tag.Get("config"); tag != "" {
+		for _, x := range strings.Split(tag, ",") {
+			switch x {
+			case "optional":
+				fi.optional = true
+			case "frozen":
+				fi.frozen = true
+			case "secret":
+				fi.secret = true
+			case "restart":
+				fi.restart = true
+			case "raw":
+				fi.raw = true
+			case "":
+			default:
+				if prefix, ok := strings.CutPrefix(x, "prefix="); ok {
+					fi.prefix = prefix
+					fi.hasPrefix = true
+					continue
+				}
+				fi.name = x
+			}
+		}
+	}
+
+	return fi
+}
+
+// isEmbedded reports whether the field f is an embedded struct whose fields
+// should be treated as options of the struct embedding it.
+func isEmbedded(f reflect.StructField) bool {
+	return f.Anonymous && f.Type.Kind() == reflect.Struct &&
+		f.Tag.Get("config") == "" && !hasParser(f.Type)
+}
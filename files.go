@@ -0,0 +1,206 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ReadFile opens the file at path and reads it into the struct obj points to,
+// as [config.Read] does.
+func ReadFile(path string, obj any, opts ...Option) error {
+	return ReadFiles([]string{path}, obj, opts...)
+}
+
+// ReadFiles reads several files into the struct obj points to, as if they
+// were one file made of each of them in order: when more than one file sets
+// an option, the last one wins. The files are parsed concurrently, as
+// [config.ParseFiles] does. Errors which don't come from a particular file
+// refer to all of paths, separated by commas.
+func ReadFiles(paths []string, obj any, opts ...Option) error {
+	o := newOptions(opts)
+	return o.finish(read(strings.Join(paths, ", "), func() ([]parsedFile, error) {
+		return parseFiles(paths, o)
+	}, obj, o))
+}
+
+// ParseFiles parses each of the files at paths and merges them in the order
+// given, so that when more than one file sets a key the last one wins. The
+// files are parsed concurrently by up to [config.WithConcurrency] workers,
+// which defaults to GOMAXPROCS. If any file can't be read or parsed, the
+// errors for all of them are returned joined, in the order of paths.
+func ParseFiles(paths []string, opts ...Option) (Values, error) {
+	o := newOptions(opts)
+	parsed, err := parseFiles(paths, o)
+	if err == nil {
+		err = checkFinal(parsed, o)
+	}
+	if err != nil {
+		return nil, o.finish(err)
+	}
+
+	merged := Values{}
+	for _, f := range parsed {
+		for key, val := range f.vals {
+			merged[key] = val
+		}
+		for key := range f.nulls {
+			delete(merged, key)
+		}
+	}
+	return merged, nil
+}
+
+// WithConcurrency sets the maximum number of files parsed at once by
+// [config.ParseFiles] and [config.ReadFiles].
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// AllowMissingFile makes [config.ReadFile], [config.ReadFiles] and
+// [config.ParseFiles] treat a file which doesn't exist as if it were empty,
+// so options come from the environment and the struct's existing values
+// instead. Any other error opening a file is still returned.
+func AllowMissingFile() Option {
+	return func(o *options) {
+		o.allowMissing = true
+	}
+}
+
+// WithFS makes [config.ReadFile], [config.ReadFiles], [config.ParseFiles] and
+// [config.ReadDir] open files from fsys instead of the operating system, for
+// platforms such as js/wasm which have no file system of their own, or to read
+// embedded files. Paths are given to fsys as they are, so they must be valid
+// for fs.FS, without a leading slash.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// Open opens the config file at path as [config.ReadFile] would, from the
+// file system given to [config.WithFS] if there is one.
+func Open(path string, opts ...Option) (fs.File, error) {
+	return newOptions(opts).open(path)
+}
+
+// open opens the file at path, from the file system given to WithFS if any.
+func (o *options) open(path string) (fs.File, error) {
+	if o.fsys != nil {
+		return o.fsys.Open(path)
+	}
+	return os.Open(path)
+}
+
+// EnforceFinal makes it an error for a file to set a key which an earlier
+// file marked final with a `# config:final` directive, when several files
+// are merged by [config.ReadFiles] or [config.ParseFiles]. This lets packaged
+// defaults which mustn't be changed, like security settings, sit alongside
+// files meant to override the rest. keys are treated as final in whichever
+// file sets them first, as if they had the directive.
+func EnforceFinal(keys ...string) Option {
+	return func(o *options) {
+		o.enforceFinal = true
+		o.finalKeys = append(o.finalKeys, keys...)
+	}
+}
+
+// checkFinal returns an error for each entry in parsed which sets a key made
+// final by an earlier file, if EnforceFinal was given.
+func checkFinal(parsed []parsedFile, o *options) error {
+	if !o.enforceFinal {
+		return nil
+	}
+
+	finalKeys := map[string]bool{}
+	for _, key := range o.finalKeys {
+		finalKeys[key] = true
+	}
+
+	// final holds the file each final key was set in.
+	final := map[string]string{}
+	var errs []error
+	for _, f := range parsed {
+		for _, e := range f.entries {
+			if path, ok := final[e.Key]; ok && path != f.path {
+				errs = append(errs, &Error{
+					File: f.path,
+					Line: e.Line,
+					Key:  e.Key,
+					Kind: KindConstraint,
+					Err:  fmt.Errorf("%v is final in %v and can't be overridden", e.Key, path),
+				})
+				continue
+			}
+
+			if finalKeys[e.Key] || slices.Contains(e.Directives, "final") {
+				final[e.Key] = f.path
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parsedFile holds the options parsed from the file at path.
+type parsedFile struct {
+	path string
+	vals Values
+	// nulls holds the keys set to null with AllowNull.
+	nulls map[string]bool
+	// entries holds the assignments in the file, in order.
+	entries []Entry
+}
+
+// parseFiles parses each of the files at paths concurrently, returning them
+// in the order of paths.
+func parseFiles(paths []string, o *options) ([]parsedFile, error) {
+	results := make([]parsedFile, len(paths))
+	errs := make([]error, len(paths))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range min(o.concurrency, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i], errs[i] = parseFile(paths[i], o)
+			}
+		}()
+	}
+
+	for i := range paths {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// parseFile opens and parses the file at path.
+func parseFile(path string, o *options) (parsedFile, error) {
+	f, err := o.open(path)
+	if errors.Is(err, fs.ErrNotExist) && o.allowMissing {
+		return parsedFile{path: path}, nil
+	} else if err != nil {
+		return parsedFile{path: path}, &Error{File: path, Kind: KindIO, Err: err}
+	}
+	defer f.Close()
+
+	o, err = fileSignature(path, o)
+	if err != nil {
+		return parsedFile{path: path}, err
+	}
+	return parseNulls(path, f, o)
+}
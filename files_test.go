@@ -0,0 +1,181 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+// writeFiles writes each of contents to its own file in a new directory, and
+// returns their paths in order.
+func writeFiles(t *testing.T, contents ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make([]string, len(contents))
+	for i, content := range contents {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%03d.conf", i))
+		if err := os.WriteFile(paths[i], []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestParseFilesOrder(t *testing.T) {
+	contents := make([]string, 200)
+	for i := range contents {
+		contents[i] = fmt.Sprintf("last = %d\nfile%d = yes\n", i, i)
+	}
+	paths := writeFiles(t, contents...)
+
+	vals, err := config.ParseFiles(paths, config.WithConcurrency(8))
+	if err != nil {
+		t.Fatalf("failed to parse files: %v", err)
+	}
+
+	if vals["last"] != "199" {
+		t.Fatalf(`expected the last file to win with "199", found "%v"`, vals["last"])
+	}
+
+	if len(vals) != 201 {
+		t.Fatalf("expected 201 values, found %v", len(vals))
+	}
+}
+
+func TestParseFilesErrors(t *testing.T) {
+	paths := writeFiles(t, "a = 1", "= 2", "c = 3")
+	paths = append(paths, filepath.Join(t.TempDir(), "missing.conf"))
+
+	_, err := config.ParseFiles(paths)
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	errs := config.Errors(err)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, found %v: %v", len(errs), err)
+	}
+
+	if errs[0].File != paths[1] || errs[0].Kind != config.KindSyntax {
+		t.Fatalf("expected a syntax error in %v, found: %v", paths[1], errs[0])
+	}
+
+	if errs[1].Kind != config.KindIO || !errors.Is(errs[1], os.ErrNotExist) {
+		t.Fatalf("expected the missing file to be reported, found: %v", errs[1])
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	paths := writeFiles(t, "host = localhost\nport = 80", "port = 8080")
+
+	var conf struct {
+		Host string
+		Port int
+	}
+	if err := config.ReadFiles(paths, &conf, config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read files into struct: %v", err)
+	}
+
+	if conf.Host != "localhost" || conf.Port != 8080 {
+		t.Fatalf("expected {localhost 8080}, found %+v", conf)
+	}
+
+	if err := config.ReadFile(paths[0], &conf, config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read file into struct: %v", err)
+	}
+
+	if conf.Port != 80 {
+		t.Fatalf("expected 80, found %v", conf.Port)
+	}
+}
+
+func TestAllowMissingFile(t *testing.T) {
+	env := config.EnvMap{"ALLOW_MISSING_PORT": "8080"}
+
+	var conf struct {
+		Port int    `config:"allow_missing_port"`
+		Host string `config:"allow_missing_host,optional"`
+	}
+	conf.Host = "localhost"
+	path := filepath.Join(t.TempDir(), "missing.conf")
+	if err := config.ReadFile(path, &conf, config.AllowMissingFile(),
+		config.WithEnvironment(env)); err != nil {
+		t.Fatalf("failed to read missing file into struct: %v", err)
+	}
+
+	if conf.Port != 8080 || conf.Host != "localhost" {
+		t.Fatalf("expected {8080 localhost}, found %+v", conf)
+	}
+
+	err := config.ReadFile(t.TempDir(), &conf, config.AllowMissingFile(), config.WithEnvironment(env))
+	if err == nil {
+		t.Fatal("expected error reading a directory, found no error")
+	}
+}
+
+func TestAllowNull(t *testing.T) {
+	paths := writeFiles(t,
+		"host = localhost\nport = 80\nname = app",
+		"host = null\nport = ~\nname = \"null\"",
+	)
+
+	vals, err := config.ParseFiles(paths, config.AllowNull())
+	if err != nil {
+		t.Fatalf("failed to parse files: %v", err)
+	}
+
+	if _, ok := vals["host"]; ok || vals["name"] != "null" {
+		t.Fatalf("expected host to be unset and name to be null, found %v", vals)
+	}
+
+	var conf struct {
+		Port int `config:"port,optional"`
+		Host string
+		Name string
+	}
+	conf.Port = 8080
+	err = config.ReadFiles(paths[1:], &conf, config.AllowNull(), config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "host" || errs[0].Kind != config.KindMissing {
+		t.Fatalf("expected host to be missing, found %v", err)
+	}
+
+	if conf.Port != 0 {
+		t.Fatalf("expected 0, found %v", conf.Port)
+	}
+}
+
+func TestEnforceFinal(t *testing.T) {
+	paths := writeFiles(t,
+		"# config:final\ntls_min_version = 1.2\nport = 80\nhost = localhost",
+		"port = 8080\ntls_min_version = 1.0",
+		"host = example.com",
+	)
+
+	vals, err := config.ParseFiles(paths)
+	if err != nil {
+		t.Fatalf("failed to parse files: %v", err)
+	}
+
+	if vals["tls_min_version"] != "1.0" {
+		t.Fatalf(`expected "1.0" without EnforceFinal, found "%v"`, vals["tls_min_version"])
+	}
+
+	_, err = config.ParseFiles(paths, config.EnforceFinal("host"))
+	errs := config.Errors(err)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, found %v: %v", len(errs), err)
+	}
+
+	if errs[0].File != paths[1] || errs[0].Line != 2 || errs[0].Key != "tls_min_version" {
+		t.Fatalf("expected tls_min_version on line 2 of %v, found %v", paths[1], errs[0])
+	}
+
+	if errs[1].File != paths[2] || errs[1].Key != "host" {
+		t.Fatalf("expected host in %v, found %v", paths[2], errs[1])
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Fingerprint returns a hash of the options in the struct obj points to, as
+// hex, which only changes when the value of an option does. Comparing
+// fingerprints shows whether processes are running with the same config
+// without logging the config itself. Secret options are left out, so the
+// fingerprint can't be used to guess them; changing only a secret leaves the
+// fingerprint the same.
+func Fingerprint(obj any) (string, error) {
+	v, err := structValue(obj)
+	if err != nil {
+		return "", err
+	}
+
+	fields := structFields(v)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].name < fields[j].name
+	})
+
+	h := sha256.New()
+	for _, fi := range fields {
+		if fi.secret {
+			continue
+		}
+
+		// Each name and value is written with its length, so that
+		// moving text from one to the other changes the hash.
+		fmt.Fprintf(h, "%d:%s", len(fi.name), fi.name)
+		if fi.v.Kind() == reflect.Pointer && fi.v.IsNil() {
+			fmt.Fprint(h, "-1:")
+			continue
+		}
+
+		val, err := formatValue(fi.v)
+		if err != nil {
+			return "", fmt.Errorf(errorWritingConfig, fi.name, err)
+		}
+		fmt.Fprintf(h, "%d:%s", len(val), val)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
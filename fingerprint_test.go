@@ -0,0 +1,37 @@
+package config_test
+
+import (
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+type fingerprintConfig struct {
+	Host  string
+	Port  int
+	Token string `config:"token,secret"`
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := func(conf fingerprintConfig) string {
+		t.Helper()
+		f, err := config.Fingerprint(&conf)
+		if err != nil {
+			t.Fatalf("failed to fingerprint config: %v", err)
+		}
+		return f
+	}
+
+	base := fingerprint(fingerprintConfig{"localhost", 8080, "a"})
+	if base != fingerprint(fingerprintConfig{"localhost", 8080, "b"}) {
+		t.Fatal("expected changing a secret to keep the fingerprint")
+	}
+
+	if base == fingerprint(fingerprintConfig{"localhost", 8081, "a"}) {
+		t.Fatal("expected changing port to change the fingerprint")
+	}
+
+	if len(base) != 64 {
+		t.Fatalf("expected 64 hex digits, found %v", base)
+	}
+}
@@ -0,0 +1,117 @@
+// package flags provides typed lookups of ad-hoc boolean toggles and other
+// feature flags, which have open-ended names that don't suit a struct:
+//
+//	vals, err := config.ParseFiles([]string{path})
+//	...
+//	f := flags.New(vals.WithPrefix("feature_flags."), "MYAPP_FEATURE_FLAGS")
+//	if f.Bool("new_ui", false) {
+//		...
+//	}
+//
+// Each lookup checks the environment variable named after the flag first, as
+// [config.Read] does, then the values, and otherwise returns the default
+// given. Values which can't be converted are treated as missing.
+package flags
+
+import (
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.eldidi.org/config"
+)
+
+// Flags holds a set of feature flags. It is safe for concurrent use, and its
+// values can be replaced with Update while it is being used, for example when
+// the config file is read again.
+type Flags struct {
+	vals      atomic.Pointer[config.Values]
+	envPrefix string
+	// env is set by SetEnvironment, and is nil for the process's
+	// environment.
+	env config.Environment
+}
+
+// New returns Flags holding vals. The environment variable for each flag is
+// named as by [config.EnvName] with envPrefix.
+func New(vals config.Values, envPrefix string) *Flags {
+	f := &Flags{envPrefix: envPrefix}
+	f.Update(vals)
+	return f
+}
+
+// SetEnvironment makes the flags be looked up in env instead of the process's
+// environment, as [config.WithEnvironment] does. It must be called before the
+// flags are used.
+func (f *Flags) SetEnvironment(env config.Environment) {
+	f.env = env
+}
+
+// Update replaces the values of the flags. Lookups after it returns see the
+// new values.
+func (f *Flags) Update(vals config.Values) {
+	f.vals.Store(&vals)
+}
+
+// Lookup returns the text of the flag name, and whether it is set.
+func (f *Flags) Lookup(name string) (string, bool) {
+	lookupEnv := os.LookupEnv
+	if f.env != nil {
+		lookupEnv = f.env.LookupEnv
+	}
+
+	if val, ok := lookupEnv(config.EnvName(f.envPrefix, name)); ok {
+		return val, true
+	}
+
+	val, ok := (*f.vals.Load())[name]
+	return val, ok
+}
+
+// String returns the flag name, or def if it isn't set.
+func (f *Flags) String(name, def string) string {
+	if val, ok := f.Lookup(name); ok {
+		return val
+	}
+	return def
+}
+
+// Bool returns the flag name as a bool, or def if it isn't set or isn't a
+// bool.
+func (f *Flags) Bool(name string, def bool) bool {
+	return lookup(f, name, def, strconv.ParseBool)
+}
+
+// Int returns the flag name as an int, or def if it isn't set or isn't an
+// int.
+func (f *Flags) Int(name string, def int) int {
+	return lookup(f, name, def, strconv.Atoi)
+}
+
+// Float returns the flag name as a float64, or def if it isn't set or isn't a
+// number.
+func (f *Flags) Float(name string, def float64) float64 {
+	return lookup(f, name, def, func(val string) (float64, error) {
+		return strconv.ParseFloat(val, 64)
+	})
+}
+
+// Duration returns the flag name as a time.Duration, or def if it isn't set
+// or isn't a duration.
+func (f *Flags) Duration(name string, def time.Duration) time.Duration {
+	return lookup(f, name, def, time.ParseDuration)
+}
+
+func lookup[T any](f *Flags, name string, def T, parse func(string) (T, error)) T {
+	val, ok := f.Lookup(name)
+	if !ok {
+		return def
+	}
+
+	x, err := parse(val)
+	if err != nil {
+		return def
+	}
+	return x
+}
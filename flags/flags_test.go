@@ -0,0 +1,50 @@
+package flags_test
+
+import (
+	"testing"
+	"time"
+
+	"go.eldidi.org/config"
+	"go.eldidi.org/config/flags"
+)
+
+func TestFlags(t *testing.T) {
+	t.Setenv("MYAPP_DARK_MODE", "true")
+
+	f := flags.New(config.Values{
+		"new_ui":      "true",
+		"max_uploads": "3",
+		"timeout":     "oops",
+	}, "MYAPP")
+
+	if !f.Bool("new_ui", false) {
+		t.Fatal("expected new_ui to be true")
+	}
+
+	if !f.Bool("dark_mode", false) {
+		t.Fatal("expected dark_mode to be true from the environment")
+	}
+
+	if f.Int("max_uploads", 1) != 3 {
+		t.Fatalf("expected 3, found %v", f.Int("max_uploads", 1))
+	}
+
+	if f.Duration("timeout", time.Second) != time.Second {
+		t.Fatalf("expected 1s, found %v", f.Duration("timeout", time.Second))
+	}
+
+	f.Update(config.Values{})
+	if f.Bool("new_ui", false) {
+		t.Fatal("expected new_ui to be false after update")
+	}
+}
+
+func TestSetEnvironment(t *testing.T) {
+	t.Setenv("MYAPP_NEW_UI", "false")
+
+	f := flags.New(config.Values{"new_ui": "false"}, "MYAPP")
+	f.SetEnvironment(config.EnvMap{"MYAPP_NEW_UI": "true"})
+	if !f.Bool("new_ui", false) {
+		t.Fatal("expected new_ui to be true from the given environment")
+	}
+}
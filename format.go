@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is a config file format, for [config.Convert].
+type Format int
+
+const (
+	// FormatNative is the format read by [config.Read].
+	FormatNative Format = iota
+	// FormatProperties is the format of Java's .properties files, read
+	// with [config.Properties].
+	FormatProperties
+	// FormatDotenv is the format of .env files, `KEY=value` lines which may
+	// start with `export`, read as [config.AllowExport] reads them. Keys
+	// are environment variable names, named from options by
+	// [config.EnvName], and are read back in lower case.
+	FormatDotenv
+	// FormatTOML is a subset of TOML: `key = value` lines and `[table]`
+	// headers, whose keys may be bare, quoted or dotted, and whose values
+	// are strings, booleans, numbers or dates on a single line. Keys in a
+	// table, or with dots, name the options of nested structs. Values
+	// other than strings are kept as the text they're written as. Arrays,
+	// inline tables, arrays of tables and multi-line strings aren't
+	// supported, and are a syntax error.
+	FormatTOML
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatNative:
+		return "native"
+	case FormatProperties:
+		return "properties"
+	case FormatDotenv:
+		return "dotenv"
+	case FormatTOML:
+		return "toml"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// Convert translates the config file read from src in the format from to the
+// format to, writing it to dst, for moving configs off a legacy format. The
+// options are written in the order they appear, along with the comments just
+// above each one and at the end of its line; other comments are lost. Keys
+// change case between dotenv and the other formats, so converting `db.host`
+// to dotenv and back gives `db_host`. TOML is written with dotted keys rather
+// than tables, and only booleans and decimal numbers are written without
+// quotes.
+func Convert(dst io.Writer, src io.Reader, from, to Format) error {
+	var opts []Option
+	switch from {
+	case FormatNative, FormatTOML:
+	case FormatProperties:
+		opts = append(opts, Properties())
+	case FormatDotenv:
+		opts = append(opts, AllowExport())
+	default:
+		return fmt.Errorf("config: unknown format %v", from)
+	}
+
+	var entries []Entry
+	var err error
+	if from == FormatTOML {
+		entries, err = parseTOML("<input>", src)
+	} else {
+		entries, err = ParseEntries("<input>", src, opts...)
+	}
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(dst)
+	for _, e := range entries {
+		key, val, err := convertEntry(e, from, to)
+		if err != nil {
+			return fmt.Errorf(errorWritingConfig, e.Key, err)
+		}
+
+		for _, comment := range e.Comments {
+			if comment == "" {
+				fmt.Fprintln(w, "#")
+				continue
+			}
+			fmt.Fprintf(w, "# %v\n", comment)
+		}
+
+		switch to {
+		case FormatDotenv:
+			fmt.Fprintf(w, "%v=%v\n", key, val)
+		default:
+			fmt.Fprintf(w, "%v = %v\n", key, val)
+		}
+	}
+	return w.Flush()
+}
+
+// convertEntry returns the key and value of e, read in the format from, as
+// they're written in the format to.
+func convertEntry(e Entry, from, to Format) (string, string, error) {
+	key := e.Key
+	if from == FormatDotenv && to != FormatDotenv {
+		key = strings.ToLower(key)
+	}
+
+	switch to {
+	case FormatNative:
+		val, err := quote(e.Value)
+		return key, val, err
+	case FormatProperties:
+		return escapeProperty(key, true), escapeProperty(e.Value, false), nil
+	case FormatDotenv:
+		if from != FormatDotenv {
+			key = EnvName("", key)
+		}
+		val, err := quoteShell(e.Value)
+		return key, val, err
+	case FormatTOML:
+		return tomlKey(key), tomlValue(e.Value), nil
+	default:
+		return "", "", fmt.Errorf("unknown format %v", to)
+	}
+}
+
+// escapeProperty escapes s to be read back by parseProperties as it is, as a
+// key if key is set and otherwise as a value.
+func escapeProperty(s string, key bool) string {
+	var b strings.Builder
+	for i, c := range s {
+		switch {
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\f':
+			b.WriteString(`\f`)
+		case c == ' ' && (key || i == 0):
+			b.WriteString(`\ `)
+		case (c == '=' || c == ':') && key:
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case (c == '#' || c == '!') && i == 0:
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// quoteShell encloses val in quotes if it wouldn't be read back as it is
+// otherwise, in a way a POSIX shell sourcing the file reads the same.
+func quoteShell(val string) (string, error) {
+	plain := val != "" && strings.IndexFunc(val, func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			strings.ContainsRune("_-./:@%+,", c))
+	}) == -1
+	switch {
+	case plain:
+		return val, nil
+	case strings.ContainsAny(val, "\r\n"):
+		return "", errMultilineValue
+	case !strings.ContainsRune(val, '\''):
+		return "'" + val + "'", nil
+	default:
+		return "", errUnrepresentable
+	}
+}
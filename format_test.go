@@ -0,0 +1,149 @@
+package config_test
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestConvert(t *testing.T) {
+	native := `# The address to listen on.
+db.host = localhost # primary only
+
+name = "my app # 1"
+path = C:\data
+`
+
+	var properties strings.Builder
+	err := config.Convert(&properties, strings.NewReader(native), config.FormatNative, config.FormatProperties)
+	if err != nil {
+		t.Fatalf("failed to convert to properties: %v", err)
+	}
+
+	expected := "# The address to listen on.\n# primary only\ndb.host = localhost\n" +
+		"name = my app # 1\npath = C:\\\\data\n"
+	if properties.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, properties.String())
+	}
+
+	var dotenv strings.Builder
+	err = config.Convert(&dotenv, strings.NewReader(properties.String()), config.FormatProperties, config.FormatDotenv)
+	if err != nil {
+		t.Fatalf("failed to convert to dotenv: %v", err)
+	}
+
+	expected = "# The address to listen on.\n# primary only\nDB_HOST=localhost\n" +
+		"NAME='my app # 1'\nPATH='C:\\data'\n"
+	if dotenv.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, dotenv.String())
+	}
+
+	var back strings.Builder
+	err = config.Convert(&back, strings.NewReader("export "+dotenv.String()), config.FormatDotenv, config.FormatNative)
+	if err != nil {
+		t.Fatalf("failed to convert to native: %v", err)
+	}
+
+	vals, err := config.Parse("<input>", strings.NewReader(back.String()))
+	if err != nil {
+		t.Fatalf("failed to parse converted config: %v", err)
+	}
+
+	if vals["db_host"] != "localhost" || vals["name"] != "my app # 1" || vals["path"] != `C:\data` {
+		t.Fatalf("expected the values to survive, found %v", vals)
+	}
+}
+
+func TestConvertTOML(t *testing.T) {
+	input := `# The service's name.
+name = "my \"app\"\t1" # quoted
+port = 8080
+debug = true
+started = 1979-05-27 07:32:00Z
+
+# The database.
+[db]
+host = 'C:\data'
+"max.conns" = 10
+pool.idle = 1.5
+`
+
+	var native strings.Builder
+	err := config.Convert(&native, strings.NewReader(input), config.FormatTOML, config.FormatNative)
+	if err != nil {
+		t.Fatalf("failed to convert from TOML: %v", err)
+	}
+
+	vals, err := config.Parse("<input>", strings.NewReader(native.String()))
+	if err != nil {
+		t.Fatalf("failed to parse converted config: %v\n%v", err, native.String())
+	}
+
+	expected := config.Values{
+		"name":         "my \"app\"\t1",
+		"port":         "8080",
+		"debug":        "true",
+		"started":      "1979-05-27 07:32:00Z",
+		"db.host":      `C:\data`,
+		"db.max.conns": "10",
+		"db.pool.idle": "1.5",
+	}
+	if !maps.Equal(vals, expected) {
+		t.Fatalf("expected %v, found %v", expected, vals)
+	}
+
+	if !strings.HasPrefix(native.String(), "# The service's name.\n# quoted\nname = ") ||
+		!strings.Contains(native.String(), "# The database.\ndb.host = ") {
+		t.Fatalf("expected the comments to be kept, found:\n%v", native.String())
+	}
+
+	var toml strings.Builder
+	err = config.Convert(&toml, strings.NewReader(native.String()), config.FormatNative, config.FormatTOML)
+	if err != nil {
+		t.Fatalf("failed to convert to TOML: %v", err)
+	}
+
+	if !strings.Contains(toml.String(), "port = 8080\n") ||
+		!strings.Contains(toml.String(), `db.host = "C:\\data"`) {
+		t.Fatalf("expected plain numbers and quoted strings, found:\n%v", toml.String())
+	}
+
+	var back strings.Builder
+	err = config.Convert(&back, strings.NewReader(toml.String()), config.FormatTOML, config.FormatNative)
+	if err != nil {
+		t.Fatalf("failed to convert from TOML: %v\n%v", err, toml.String())
+	}
+
+	vals, err = config.Parse("<input>", strings.NewReader(back.String()))
+	if err != nil {
+		t.Fatalf("failed to parse converted config: %v", err)
+	}
+
+	if !maps.Equal(vals, expected) {
+		t.Fatalf("expected %v, found %v", expected, vals)
+	}
+}
+
+func TestConvertTOMLUnsupported(t *testing.T) {
+	tests := []string{
+		"hosts = [\"a\", \"b\"]",
+		"db = { host = \"a\" }",
+		"[[servers]]",
+		"motd = \"\"\"",
+		"name = unquoted",
+		"name = \"unterminated",
+		"name = \"\\x\"",
+		"port = 8080 extra",
+	}
+
+	for _, test := range tests {
+		var out strings.Builder
+		err := config.Convert(&out, strings.NewReader(test), config.FormatTOML, config.FormatNative)
+		if !errors.Is(err, config.ErrSyntax) {
+			t.Fatalf("expected a syntax error for %q, found %v", test, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Guard detects changes to a config after it has been read, as returned by
+// [config.Freeze].
+type Guard struct {
+	v      reflect.Value
+	fields []fieldInfo
+	sums   []uint64
+}
+
+// Freeze records a checksum of each option of the struct obj points to, so
+// that Check can later report any which have changed. The checksums follow
+// pointers, slices and maps, so changes to the values they refer to are
+// detected too.
+//
+// It's meant for tests and debug builds, to find code which modifies a config
+// which is supposed to be shared and unchanging.
+func Freeze(obj any) (*Guard, error) {
+	v, err := structValue(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Guard{v: v, fields: typeFields(v.Type())}
+	g.sums = g.checksums()
+	return g, nil
+}
+
+// Check returns an error naming the options which have changed since the
+// config was frozen, or nil if none have.
+func (g *Guard) Check() error {
+	var changed []string
+	for i, sum := range g.checksums() {
+		if sum != g.sums[i] {
+			changed = append(changed, g.fields[i].name)
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("config changed after it was frozen: %v", strings.Join(changed, ", "))
+}
+
+func (g *Guard) checksums() []uint64 {
+	sums := make([]uint64, len(g.fields))
+	for i, fi := range g.fields {
+		h := fnv.New64a()
+		if field, err := g.v.FieldByIndexErr(fi.index); err == nil {
+			hashValue(h, field, map[uintptr]bool{})
+		}
+		sums[i] = h.Sum64()
+	}
+	return sums
+}
+
+// hashValue writes the contents of v to h. seen holds the pointers followed to
+// reach v, so cycles end.
+func hashValue(h hash.Hash64, v reflect.Value, seen map[uintptr]bool) {
+	var buf [8]byte
+	writeUint := func(x uint64) {
+		binary.LittleEndian.PutUint64(buf[:], x)
+		h.Write(buf[:])
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(math.Float64bits(real(v.Complex())))
+		writeUint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		writeUint(uint64(v.Len()))
+		h.Write([]byte(v.String()))
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			writeUint(0)
+			return
+		}
+
+		writeUint(1)
+		if v.Kind() == reflect.Pointer {
+			if seen[v.Pointer()] {
+				return
+			}
+			seen[v.Pointer()] = true
+			defer delete(seen, v.Pointer())
+		}
+		hashValue(h, v.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		writeUint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i += 1 {
+			hashValue(h, v.Index(i), seen)
+		}
+	case reflect.Map:
+		// Map iteration order is random, so hash each entry separately
+		// and combine them in a fixed order.
+		entries := make([]uint64, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			e := fnv.New64a()
+			hashValue(e, iter.Key(), seen)
+			hashValue(e, iter.Value(), seen)
+			entries = append(entries, e.Sum64())
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i] < entries[j] })
+
+		writeUint(uint64(len(entries)))
+		for _, e := range entries {
+			writeUint(e)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i += 1 {
+			hashValue(h, v.Field(i), seen)
+		}
+	default:
+		// Channels, functions and unsafe pointers have no contents to
+		// compare, so only their identity is checked.
+		if v.IsValid() && !v.IsZero() {
+			writeUint(uint64(v.Pointer()))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestFreeze(t *testing.T) {
+	var conf struct {
+		Port  int
+		Hosts map[string]*string `config:"hosts,optional"`
+		Tags  []string           `config:"tags,optional"`
+	}
+	host := "localhost"
+	conf.Port = 8080
+	conf.Hosts = map[string]*string{"a": &host, "b": &host}
+	conf.Tags = []string{"x"}
+
+	g, err := config.Freeze(&conf)
+	if err != nil {
+		t.Fatalf("failed to freeze config: %v", err)
+	}
+
+	if err := g.Check(); err != nil {
+		t.Fatalf("expected no error, found %v", err)
+	}
+
+	host = "example.com"
+	conf.Tags[0] = "y"
+	err = g.Check()
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	if !strings.Contains(err.Error(), "hosts, tags") || strings.Contains(err.Error(), "port") {
+		t.Fatalf(`expected "hosts, tags" in error, found "%v"`, err)
+	}
+}
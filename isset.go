@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+)
+
+// IsSet reports whether the option key was set by any layer, rather than
+// keeping the value the struct had.
+func (r *Report) IsSet(key string) bool {
+	_, ok := r.Sources[key]
+	return ok
+}
+
+// IsSet reports whether v holds a value for key.
+func (v Values) IsSet(key string) bool {
+	_, ok := v[key]
+	return ok
+}
+
+// WithWasSet makes reading record which options were set in the struct set
+// points to, a companion of the struct being read with a bool field for each
+// option that should be tracked. The fields of set are named like those of
+// the struct being read, with the same `config:""` struct tags and nesting,
+// and each is set to whether its option was set by any layer, so a program
+// can tell an option the user configured from one left at its default
+// without making it a pointer:
+//
+//	type Config struct {
+//		Port    int `config:"port,optional"`
+//		Workers int `config:"workers,optional"`
+//	}
+//
+//	type ConfigSet struct {
+//		Port    bool `config:"port"`
+//		Workers bool `config:"workers"`
+//	}
+//
+// Fields of set which aren't bools, or whose names aren't options, are left
+// alone. set is filled in even if reading fails.
+func WithWasSet(set any) Option {
+	return func(o *options) {
+		o.wasSet = set
+	}
+}
+
+// fillWasSet sets each bool field of the struct set points to, to whether
+// the option of the same name is in s.vals. Only names in fields, the options
+// of the struct being read, are filled in. prefix is the section being read.
+func (s *readState) fillWasSet(set any, prefix string, fields []fieldInfo) {
+	v, err := structValue(set)
+	if err != nil {
+		return
+	}
+
+	options := make(map[string]bool, len(fields))
+	for _, fi := range fields {
+		options[fi.name] = true
+	}
+
+	for _, fi := range s.fieldValues(v, sectionFields(v.Type(), prefix)) {
+		if fi.setter || fi.v.Kind() != reflect.Bool || !options[fi.name] {
+			continue
+		}
+
+		_, ok := s.vals[fi.name]
+		fi.v.SetBool(ok)
+	}
+}
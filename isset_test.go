@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestIsSet(t *testing.T) {
+	var conf struct {
+		Host string `config:"host"`
+		Port int    `config:"port,optional"`
+	}
+	var report config.Report
+	err := config.Read("<input>", strings.NewReader(`
+	host = localhost
+	`), &conf, config.WithReport(&report), config.WithPrecedence(config.LayerFile))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if !report.IsSet("host") || !report.Values.IsSet("host") {
+		t.Fatalf("expected host to be set")
+	}
+
+	if report.IsSet("port") || report.Values.IsSet("port") {
+		t.Fatalf("expected port not to be set")
+	}
+}
+
+func TestWithWasSet(t *testing.T) {
+	type server struct {
+		Port int `config:"port,optional"`
+	}
+	var conf struct {
+		Host    string `config:"host"`
+		Workers int    `config:"workers,optional"`
+		Server  server
+	}
+	var set struct {
+		Host    bool `config:"host"`
+		Workers bool `config:"workers"`
+		Server  struct {
+			Port bool `config:"port"`
+		}
+		Other   string
+		Verbose bool `config:"verbose"`
+	}
+	set.Workers = true
+	set.Other = "unchanged"
+	set.Verbose = true
+
+	err := config.Read("<input>", strings.NewReader(`
+	host = localhost
+	server.port = 8080
+	verbose = true
+	`), &conf, config.WithWasSet(&set), config.WithPrecedence(config.LayerFile))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if !set.Host || set.Workers || !set.Server.Port {
+		t.Fatalf("expected host and server.port to be set only, found %+v", set)
+	}
+
+	if set.Other != "unchanged" {
+		t.Fatalf(`expected "unchanged", found "%v"`, set.Other)
+	}
+
+	if !set.Verbose {
+		t.Fatal("expected verbose, which isn't an option, to be left alone")
+	}
+
+	set.Verbose = false
+	err = config.Read("<input>", strings.NewReader(`
+	host = localhost
+	verbose = true
+	`), &conf, config.WithWasSet(&set), config.WithPrecedence(config.LayerFile))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if set.Verbose {
+		t.Fatal("expected verbose, which isn't an option, to be left alone")
+	}
+}
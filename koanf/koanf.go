@@ -0,0 +1,123 @@
+// package koanf lets config files be loaded by github.com/knadh/koanf. It
+// implements koanf's Provider and Parser interfaces without depending on it:
+//
+//	k := koanf.New(".")
+//	k.Load(cfgkoanf.Provider("app.conf"), nil)
+//	k.Load(file.Provider("app.conf"), cfgkoanf.Parser())
+//
+// Keys containing dots are split into nested maps, as koanf expects.
+package koanf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"go.eldidi.org/config"
+)
+
+// FileProvider is a koanf Provider which reads and parses a config file.
+type FileProvider struct {
+	path string
+	opts []config.Option
+}
+
+// Provider returns a FileProvider for the config file at path, parsed with
+// opts.
+func Provider(path string, opts ...config.Option) *FileProvider {
+	return &FileProvider{path: path, opts: opts}
+}
+
+// ReadBytes returns the contents of the file, from the fs.FS given to
+// [config.WithFS] if there is one.
+func (p *FileProvider) ReadBytes() ([]byte, error) {
+	f, err := config.Open(p.path, p.opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// Read returns the parsed options of the file as nested maps.
+func (p *FileProvider) Read() (map[string]any, error) {
+	vals, err := config.ParseFiles([]string{p.path}, p.opts...)
+	if err != nil {
+		return nil, err
+	}
+	return unflatten(vals), nil
+}
+
+// FormatParser is a koanf Parser for the config file format.
+type FormatParser struct {
+	opts []config.Option
+}
+
+// Parser returns a FormatParser which parses with opts.
+func Parser(opts ...config.Option) *FormatParser {
+	return &FormatParser{opts: opts}
+}
+
+// Unmarshal parses b into nested maps.
+func (p *FormatParser) Unmarshal(b []byte) (map[string]any, error) {
+	vals, err := config.Parse("<koanf>", bytes.NewReader(b), p.opts...)
+	if err != nil {
+		return nil, err
+	}
+	return unflatten(vals), nil
+}
+
+// Marshal writes m in the config file format, joining the keys of nested maps
+// with dots. Values which aren't strings are written with fmt.Sprint.
+func (p *FormatParser) Marshal(m map[string]any) ([]byte, error) {
+	vals := config.Values{}
+	flatten(vals, "", m)
+
+	var b bytes.Buffer
+	if err := config.WriteValues(&b, vals); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func unflatten(vals config.Values) map[string]any {
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+	// Shorter keys first, so a.b is placed before a.b.c replaces it with a
+	// map, the same way koanf resolves the conflict.
+	sort.Strings(keys)
+
+	out := map[string]any{}
+	for _, key := range keys {
+		m := out
+		parts := strings.Split(key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := m[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				m[part] = next
+			}
+			m = next
+		}
+		m[parts[len(parts)-1]] = vals[key]
+	}
+	return out
+}
+
+func flatten(vals config.Values, prefix string, m map[string]any) {
+	for key, val := range m {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if sub, ok := val.(map[string]any); ok {
+			flatten(vals, key, sub)
+			continue
+		}
+		vals[key] = fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,78 @@
+package koanf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"go.eldidi.org/config"
+	"go.eldidi.org/config/koanf"
+)
+
+func TestProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	err := os.WriteFile(path, []byte("name = app\ndb.host = localhost\ndb.port = 5432\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := koanf.Provider(path).Read()
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	if m["name"] != "app" {
+		t.Fatalf(`expected "app", found "%v"`, m["name"])
+	}
+
+	db, ok := m["db"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected db to be a map, found %T", m["db"])
+	}
+
+	if db["host"] != "localhost" || db["port"] != "5432" {
+		t.Fatalf("expected {localhost 5432}, found %v", db)
+	}
+}
+
+func TestParserRoundTrip(t *testing.T) {
+	p := koanf.Parser()
+	b, err := p.Marshal(map[string]any{
+		"greeting": "# hello",
+		"db":       map[string]any{"port": 5432},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	expected := "db.port = 5432\ngreeting = \"# hello\"\n"
+	if string(b) != expected {
+		t.Fatalf(`expected "%v", found "%v"`, expected, string(b))
+	}
+
+	m, err := p.Unmarshal(b)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if m["greeting"] != "# hello" {
+		t.Fatalf(`expected "# hello", found "%v"`, m["greeting"])
+	}
+
+	if m["db"].(map[string]any)["port"] != "5432" {
+		t.Fatalf(`expected "5432", found "%v"`, m["db"])
+	}
+}
+
+func TestProviderFS(t *testing.T) {
+	fsys := fstest.MapFS{"app.conf": {Data: []byte("name = app\n")}}
+	b, err := koanf.Provider("app.conf", config.WithFS(fsys)).ReadBytes()
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	if string(b) != "name = app\n" {
+		t.Fatalf(`expected "name = app", found "%s"`, b)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Lazy holds an option whose value is only converted to T the first time it
+// is used, for configs with many options which are rarely needed. Reading a
+// config only records the text of a Lazy option, so any error converting it
+// is returned by Get instead of by Read.
+//
+// A Lazy is safe for concurrent use, and can be copied.
+type Lazy[T any] struct {
+	v *lazyValue[T]
+}
+
+type lazyValue[T any] struct {
+	key  string
+	raw  string
+	all  Values
+	once sync.Once
+	val  T
+	err  error
+}
+
+func (l *Lazy[T]) ParseConfigValue(key string, all Values) error {
+	l.v = &lazyValue[T]{
+		key: key,
+		raw: all[key],
+		all: all,
+	}
+	return nil
+}
+
+// Get returns the value of the option, converting it the first time it's
+// called. If the option wasn't set, Get returns the zero value of T.
+func (l Lazy[T]) Get() (T, error) {
+	if l.v == nil {
+		var zero T
+		return zero, nil
+	}
+
+	l.v.once.Do(func() {
+		field := reflect.ValueOf(&l.v.val).Elem()
+		err := readField(l.v.key, l.v.raw, l.v.all, field)
+		if err != nil {
+			l.v.err = fmt.Errorf("invalid value for %v: %w", l.v.key, err)
+		}
+	})
+	return l.v.val, l.v.err
+}
+
+// lazyType is implemented by Lazy, so that struct tags such as `number:""`
+// can check the type it converts to.
+type lazyType interface {
+	valueType() reflect.Type
+}
+
+func (Lazy[T]) valueType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// ConfigValue returns the text the option was given as.
+func (l Lazy[T]) ConfigValue() (string, error) {
+	if l.v == nil {
+		return "", nil
+	}
+	return l.v.raw, nil
+}
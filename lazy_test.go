@@ -0,0 +1,44 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.eldidi.org/config"
+)
+
+func TestLazy(t *testing.T) {
+	var conf struct {
+		Retries config.Lazy[int]
+		Broken  config.Lazy[int]
+		Zone    config.Lazy[*time.Location] `config:"zone,optional"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	retries = 3
+	broken = three
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	retries, err := conf.Retries.Get()
+	if err != nil {
+		t.Fatalf("failed to convert retries: %v", err)
+	}
+
+	if retries != 3 {
+		t.Fatalf("expected 3, found %v", retries)
+	}
+
+	if _, err := conf.Broken.Get(); err == nil {
+		t.Fatal("expected error, found no error")
+	} else if !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("expected the error to name the key, found: %v", err)
+	}
+
+	zone, err := conf.Zone.Get()
+	if err != nil || zone != nil {
+		t.Fatalf("expected nil for an unset option, found %v, %v", zone, err)
+	}
+}
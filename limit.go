@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrReadLimit means the input was larger than [config.WithReadLimit]
+	// allows.
+	ErrReadLimit = errors.New("input is larger than the read limit")
+	// ErrReadTimeout means the input wasn't read within the time
+	// [config.WithReadTimeout] allows.
+	ErrReadTimeout = errors.New("input wasn't read within the read timeout")
+)
+
+// WithReadLimit makes it an error for a config file or stream to be larger
+// than n bytes, so that untrusted input, such as a config uploaded by a
+// tenant, can't exhaust memory. Reading stops as soon as the limit is passed,
+// and the error, of kind KindIO, matches ErrReadLimit.
+func WithReadLimit(n int64) Option {
+	return func(o *options) {
+		o.readLimit = n
+	}
+}
+
+// WithReadTimeout makes it an error for reading a config file or stream to
+// take longer than d, so that a reader such as a network connection which
+// stops sending can't block forever. The error, of kind KindIO, matches
+// ErrReadTimeout. A read from the underlying reader which is still blocked
+// when the timeout passes is left running in the background, so a reader
+// which can be closed should be closed after such an error.
+func WithReadTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.readTimeout = d
+	}
+}
+
+// guardReader returns r wrapped to enforce the read limit and timeout in o.
+func guardReader(r io.Reader, o *options) io.Reader {
+	if o.readLimit > 0 {
+		r = &limitedReader{r: r, n: o.readLimit}
+	}
+	if o.readTimeout > 0 {
+		r = &deadlineReader{r: r, deadline: time.Now().Add(o.readTimeout)}
+	}
+	return r
+}
+
+// limitedReader reads from r, returning ErrReadLimit once more than n bytes
+// have been read.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, ErrReadLimit
+	}
+
+	// Reading one byte past the limit tells an input of exactly n bytes
+	// apart from a larger one. The check subtracts from len(p) rather
+	// than adding to n, since n+1 overflows for a limit of math.MaxInt64.
+	if l.n < int64(len(p))-1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n - 1, ErrReadLimit
+	}
+	return n, err
+}
+
+// deadlineReader reads from r, returning ErrReadTimeout once deadline has
+// passed.
+type deadlineReader struct {
+	r        io.Reader
+	deadline time.Time
+}
+
+// readResult is the result of a call to Read.
+type readResult struct {
+	n   int
+	err error
+}
+
+func (d *deadlineReader) Read(p []byte) (int, error) {
+	remaining := time.Until(d.deadline)
+	if remaining <= 0 {
+		return 0, ErrReadTimeout
+	}
+
+	// The read gets its own buffer, since it may still be writing to it
+	// after the timeout, when p belongs to the caller again.
+	buf := make([]byte, len(p))
+	done := make(chan readResult, 1)
+	go func() {
+		n, err := d.r.Read(buf)
+		done <- readResult{n, err}
+	}()
+
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return copy(p, buf[:res.n]), res.err
+	case <-timer.C:
+		return 0, ErrReadTimeout
+	}
+}
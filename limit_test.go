@@ -0,0 +1,50 @@
+package config_test
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"go.eldidi.org/config"
+)
+
+func TestWithReadLimit(t *testing.T) {
+	input := "host = localhost\nport = 8080\n"
+	if _, err := config.Parse("<input>", strings.NewReader(input), config.WithReadLimit(int64(len(input)))); err != nil {
+		t.Fatalf("failed to parse input within the limit: %v", err)
+	}
+
+	_, err := config.Parse("<input>", strings.NewReader(input), config.WithReadLimit(10))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Kind != config.KindIO || !errors.Is(err, config.ErrReadLimit) {
+		t.Fatalf("expected ErrReadLimit, found %v", err)
+	}
+	vals, err := config.Parse("<input>", strings.NewReader(input), config.WithReadLimit(math.MaxInt64))
+	if err != nil || vals["port"] != "8080" {
+		t.Fatalf("failed to parse input with the largest limit: %v", err)
+	}
+}
+
+func TestWithReadTimeout(t *testing.T) {
+	r, w := io.Pipe()
+	defer r.Close()
+	go w.Write([]byte("port = 8080\n"))
+
+	var conf struct {
+		Port int
+	}
+	err := config.Read("<input>", r, &conf, config.WithReadTimeout(50*time.Millisecond),
+		config.WithEnvironment(config.EnvMap{}))
+	if !errors.Is(err, config.ErrReadTimeout) {
+		t.Fatalf("expected ErrReadTimeout, found %v", err)
+	}
+
+	err = config.Read("<input>", strings.NewReader("port = 8080"), &conf, config.WithReadTimeout(time.Second),
+		config.WithEnvironment(config.EnvMap{}))
+	if err != nil || conf.Port != 8080 {
+		t.Fatalf("expected 8080 without error, found %v: %v", conf.Port, err)
+	}
+}
@@ -0,0 +1,294 @@
+// package lint checks config files for likely mistakes, such as keys set
+// twice, keys which aren't options, and credentials written in plain text, so
+// they can be caught in CI or a pre-commit hook:
+//
+//	findings, err := lint.LintFile("app.conf", &Config{})
+//	...
+//	for _, f := range findings {
+//		fmt.Println(f)
+//	}
+//
+// Each check is a [Rule] registered under a name. The built-in rules are
+// described by [Rules], and more can be added with [Register].
+package lint
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.eldidi.org/config"
+)
+
+// Finding is a problem found in a config file by a rule.
+type Finding struct {
+	// Rule is the name of the rule which found the problem.
+	Rule string
+	File string
+	// Line is the line the problem was found on, or 0 if it isn't tied to
+	// a particular line.
+	Line    int
+	Key     string
+	Message string
+}
+
+func (f Finding) String() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%v:%v: %v (%v)", f.File, f.Line, f.Message, f.Rule)
+	}
+	return fmt.Sprintf("%v: %v (%v)", f.File, f.Message, f.Rule)
+}
+
+// File is a parsed config file, as given to each rule.
+type File struct {
+	Path string
+	// Entries holds the assignments in the file, as returned by
+	// [config.ParseEntries].
+	Entries []config.Entry
+	// Fields holds the options of the struct the file is read into, as
+	// returned by [config.Fields]. It is nil if the file is checked
+	// without a struct, and rules which need it find nothing.
+	Fields []config.Field
+}
+
+// field returns the option named key, if f has a struct.
+func (f *File) field(key string) (config.Field, bool) {
+	for _, field := range f.Fields {
+		if field.Name == key {
+			return field, true
+		}
+	}
+	return config.Field{}, false
+}
+
+// Rule checks f, returning a finding for each problem. Rule and File are
+// filled in for it, if left empty.
+type Rule func(f *File) []Finding
+
+var (
+	rulesMu sync.RWMutex
+	// rules holds the rules run by Lint, by name.
+	rules = map[string]Rule{
+		"duplicate":        duplicateKeys,
+		"unknown":          unknownKeys,
+		"public-admin":     publicAdmin,
+		"plaintext-secret": plaintextSecrets,
+	}
+)
+
+// Register makes rule available under the given name, so it is run by
+// [Lint] along with the built-in rules.
+//
+// Register panics if rule is nil, if the name is empty, or if a rule with
+// that name already exists.
+func Register(name string, rule Rule) {
+	if rule == nil {
+		panic("lint: Register called with nil rule")
+	}
+
+	if name == "" {
+		panic("lint: invalid rule name ''")
+	}
+
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	if _, dup := rules[name]; dup {
+		panic("lint: Register called twice for rule '" + name + "'")
+	}
+
+	rules[name] = rule
+}
+
+// Rules returns the names of the registered rules, sorted. The built-in
+// rules are:
+//
+//   - duplicate: a key is set more than once in the file, so only the last
+//     value is used.
+//   - unknown: a key isn't an option of the struct, which usually means a
+//     typo.
+//   - public-admin: an option whose key mentions admin or debug listens on
+//     every interface, as with `0.0.0.0:8081` or `:8081`.
+//   - plaintext-secret: an option holding a credential is set in the file,
+//     rather than from the environment. Options are credentials if they're
+//     tagged secret, or if the file has no struct and the key mentions a
+//     password, secret, token or key.
+func Rules() []string {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lint runs the rules named by names against f, or every registered rule if
+// no names are given, and returns the findings sorted by line. Entries with a
+// `# config:nolint` directive are left out of the findings. An error is
+// returned if a rule isn't registered.
+func Lint(f *File, names ...string) ([]Finding, error) {
+	if len(names) == 0 {
+		names = Rules()
+	}
+
+	nolint := map[int]bool{}
+	for _, e := range f.Entries {
+		if slices.Contains(e.Directives, "nolint") {
+			nolint[e.Line] = true
+		}
+	}
+
+	var findings []Finding
+	for _, name := range names {
+		rulesMu.RLock()
+		rule, ok := rules[name]
+		rulesMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("lint: unknown rule '%v'", name)
+		}
+
+		for _, finding := range rule(f) {
+			if finding.Rule == "" {
+				finding.Rule = name
+			}
+			if finding.File == "" {
+				finding.File = f.Path
+			}
+			if !nolint[finding.Line] {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, nil
+}
+
+// LintFile parses the file at path with opts and runs every registered rule
+// against it. The file is opened from the fs.FS given to [config.WithFS], if
+// there is one. obj is a pointer to the struct the file is read into, as given
+// to [config.Read], or nil to check the file on its own.
+func LintFile(path string, obj any, opts ...config.Option) ([]Finding, error) {
+	r, err := config.Open(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	f := &File{Path: path}
+	if f.Entries, err = config.ParseEntries(path, r, opts...); err != nil {
+		return nil, err
+	}
+
+	if obj != nil {
+		if f.Fields, err = config.Fields(obj); err != nil {
+			return nil, err
+		}
+	}
+	return Lint(f)
+}
+
+// duplicateKeys finds keys set more than once.
+func duplicateKeys(f *File) []Finding {
+	var findings []Finding
+	first := map[string]int{}
+	for _, e := range f.Entries {
+		line, ok := first[e.Key]
+		if !ok {
+			first[e.Key] = e.Line
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Line:    e.Line,
+			Key:     e.Key,
+			Message: fmt.Sprintf("%v is already set on line %v", e.Key, line),
+		})
+	}
+	return findings
+}
+
+// unknownKeys finds keys which aren't options of the struct.
+func unknownKeys(f *File) []Finding {
+	if f.Fields == nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, e := range f.Entries {
+		if _, ok := f.field(e.Key); !ok {
+			findings = append(findings, Finding{
+				Line:    e.Line,
+				Key:     e.Key,
+				Message: fmt.Sprintf("unknown option %v", e.Key),
+			})
+		}
+	}
+	return findings
+}
+
+// publicAdmin finds admin and debug listeners on every interface.
+func publicAdmin(f *File) []Finding {
+	var findings []Finding
+	for _, e := range f.Entries {
+		key := strings.ToLower(e.Key)
+		if !strings.Contains(key, "admin") && !strings.Contains(key, "debug") {
+			continue
+		}
+
+		host, _, err := net.SplitHostPort(e.Value)
+		if err != nil {
+			host = e.Value
+		}
+		if (host == "" && err == nil) || host == "0.0.0.0" || host == "::" {
+			findings = append(findings, Finding{
+				Line:    e.Line,
+				Key:     e.Key,
+				Message: fmt.Sprintf("%v listens on every interface", e.Key),
+			})
+		}
+	}
+	return findings
+}
+
+// secretWords are the words in a key which suggest it holds a credential.
+var secretWords = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "private_key"}
+
+// plaintextSecrets finds credentials set in the file.
+func plaintextSecrets(f *File) []Finding {
+	var findings []Finding
+	for _, e := range f.Entries {
+		if e.Null || e.Value == "" || !isSecret(f, e.Key) {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Line:    e.Line,
+			Key:     e.Key,
+			Message: fmt.Sprintf("%v holds a credential in plain text, set it from the environment instead", e.Key),
+		})
+	}
+	return findings
+}
+
+// isSecret reports whether the option key holds a credential.
+func isSecret(f *File, key string) bool {
+	if f.Fields != nil {
+		field, ok := f.field(key)
+		return ok && field.Secret
+	}
+
+	key = strings.ToLower(key)
+	for _, word := range secretWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
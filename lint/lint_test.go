@@ -0,0 +1,96 @@
+package lint_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go.eldidi.org/config"
+	"go.eldidi.org/config/lint"
+)
+
+func TestLintFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	err := os.WriteFile(path, []byte(`port = 8080
+admin_addr = 0.0.0.0:9090
+password = hunter2
+prot = 8081
+port = 8081
+# config:nolint
+debug_addr = :6060
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conf struct {
+		Port      int
+		AdminAddr string
+		DebugAddr string
+		Password  string `config:"password,secret"`
+	}
+	findings, err := lint.LintFile(path, &conf)
+	if err != nil {
+		t.Fatalf("failed to lint file: %v", err)
+	}
+
+	expected := []string{
+		path + ":2: admin_addr listens on every interface (public-admin)",
+		path + ":3: password holds a credential in plain text, set it from the environment instead (plaintext-secret)",
+		path + ":4: unknown option prot (unknown)",
+		path + ":5: port is already set on line 1 (duplicate)",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %v findings, found %v: %v", len(expected), len(findings), findings)
+	}
+
+	for i, f := range findings {
+		if f.String() != expected[i] {
+			t.Fatalf(`expected "%v", found "%v"`, expected[i], f)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	lint.Register("test-empty", func(f *lint.File) []lint.Finding {
+		var findings []lint.Finding
+		for _, e := range f.Entries {
+			if e.Value == "" {
+				findings = append(findings, lint.Finding{Line: e.Line, Key: e.Key, Message: e.Key + " is empty"})
+			}
+		}
+		return findings
+	})
+
+	entries, err := config.ParseEntries("<input>", strings.NewReader("api_token = abc\nname ="))
+	if err != nil {
+		t.Fatalf("failed to parse entries: %v", err)
+	}
+
+	findings, err := lint.Lint(&lint.File{Path: "<input>", Entries: entries})
+	if err != nil {
+		t.Fatalf("failed to lint: %v", err)
+	}
+
+	if len(findings) != 2 || findings[0].Rule != "plaintext-secret" || findings[1].Rule != "test-empty" {
+		t.Fatalf("expected plaintext-secret and test-empty findings, found %v", findings)
+	}
+
+	if _, err := lint.Lint(&lint.File{}, "missing"); err == nil {
+		t.Fatal("expected error for an unknown rule, found no error")
+	}
+}
+
+func TestLintFileFS(t *testing.T) {
+	fsys := fstest.MapFS{"etc/app.conf": {Data: []byte("port = 1\nport = 2\n")}}
+	findings, err := lint.LintFile("etc/app.conf", nil, config.WithFS(fsys))
+	if err != nil {
+		t.Fatalf("failed to lint file: %v", err)
+	}
+
+	if len(findings) != 1 || findings[0].Rule != "duplicate" {
+		t.Fatalf("expected a duplicate finding, found %v", findings)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+)
+
+// VersionKey is the option a config file sets to the version of the schema it
+// was written for, as used by [config.WithMigrations].
+const VersionKey = "config_version"
+
+// Migration upgrades the options of a file written for one version of the
+// schema to the next version, for example by renaming keys or converting
+// values. vals may be modified and returned.
+type Migration func(vals Values) (Values, error)
+
+// WithMigrations makes reading upgrade each file written for an older version
+// of the schema than current before its options are used, so files keep
+// working after a breaking change to the struct. A file gives its version in
+// the `config_version` option, and a file without it is version 0. The
+// migration in migrations for each version from the file's up to current is
+// applied in turn, so migrations[1] upgrades a file from version 1 to 2.
+//
+// The `config_version` option itself is removed once the file is upgraded.
+// It is an error for a file to have a version newer than current, or for a
+// migration it needs to be missing.
+func WithMigrations(current int, migrations map[int]Migration) Option {
+	return func(o *options) {
+		o.schemaVersion = current
+		o.migrations = map[int]Migration{}
+		maps.Copy(o.migrations, migrations)
+	}
+}
+
+// migrate returns the options in f upgraded to the version in o.
+func migrate(f parsedFile, o *options) (Values, error) {
+	vals := maps.Clone(f.vals)
+	version := 0
+	if text, ok := vals[VersionKey]; ok {
+		var err error
+		if version, err = strconv.Atoi(text); err != nil || version < 0 {
+			return nil, invalidError(f.path, VersionKey, fmt.Errorf("'%v' isn't a version number", text))
+		}
+		delete(vals, VersionKey)
+	}
+
+	if version > o.schemaVersion {
+		return nil, newError(f.path, VersionKey, KindInvalid,
+			fmt.Errorf("the file is for version %v, which is newer than version %v", version, o.schemaVersion))
+	}
+
+	for ; version < o.schemaVersion; version += 1 {
+		m, ok := o.migrations[version]
+		if !ok {
+			return nil, newError(f.path, VersionKey, KindUnsupported,
+				fmt.Errorf("no migration from version %v to %v", version, version+1))
+		}
+
+		var err error
+		if vals, err = m(vals); err != nil {
+			return nil, newError(f.path, VersionKey, KindInvalid,
+				fmt.Errorf("migrating from version %v: %w", version, err))
+		}
+	}
+	return vals, nil
+}
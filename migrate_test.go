@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestWithMigrations(t *testing.T) {
+	migrations := map[int]config.Migration{
+		// Version 1 renamed listen to addr.
+		0: func(vals config.Values) (config.Values, error) {
+			vals["addr"] = vals["listen"]
+			delete(vals, "listen")
+			return vals, nil
+		},
+		// Version 2 changed timeout from seconds to a duration.
+		1: func(vals config.Values) (config.Values, error) {
+			if timeout, ok := vals["timeout"]; ok {
+				vals["timeout"] = timeout + "s"
+			}
+			return vals, nil
+		},
+	}
+
+	var conf struct {
+		Addr    string
+		Timeout string
+	}
+	inputs := []string{
+		"listen = :8080\ntimeout = 30",
+		"config_version = 1\naddr = :8080\ntimeout = 30",
+		"config_version = 2\naddr = :8080\ntimeout = 30s",
+	}
+	for _, input := range inputs {
+		conf.Addr, conf.Timeout = "", ""
+		err := config.Read("<input>", strings.NewReader(input), &conf,
+			config.WithMigrations(2, migrations), config.DisallowUnknownKeys(),
+			config.WithEnvironment(config.EnvMap{}))
+		if err != nil {
+			t.Fatalf("failed to read %q: %v", input, err)
+		}
+
+		if conf.Addr != ":8080" || conf.Timeout != "30s" {
+			t.Fatalf("expected {:8080 30s}, found %+v", conf)
+		}
+	}
+
+	err := config.Read("<input>", strings.NewReader("config_version = 3"), &conf,
+		config.WithMigrations(2, migrations), config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "config_version" || errs[0].Kind != config.KindInvalid {
+		t.Fatalf("expected config_version to be invalid, found %v", err)
+	}
+
+	failed := errors.New("failed")
+	migrations[1] = func(config.Values) (config.Values, error) { return nil, failed }
+	err = config.Read("<input>", strings.NewReader("config_version = 1"), &conf,
+		config.WithMigrations(2, migrations), config.WithEnvironment(config.EnvMap{}))
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected the migration's error, found %v", err)
+	}
+}
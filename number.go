@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// numberFormat is a way of writing numbers, which can be given in the
+// `number:""` struct tag.
+type numberFormat struct {
+	// decimal separates the whole part of a number from its fraction.
+	decimal string
+	// groups holds the characters which may separate groups of three
+	// digits in the whole part, including the non-breaking spaces some
+	// locales use.
+	groups string
+	// example is a number written in the format, for errors.
+	example string
+}
+
+// numberFormats holds the formats for the `number:""` struct tag, by name.
+var numberFormats = map[string]numberFormat{
+	"comma": {decimal: ",", groups: ". '\u00a0\u202f", example: "1.234,5"},
+	"point": {decimal: ".", groups: ", '\u00a0\u202f", example: "1,234.5"},
+}
+
+// number returns val, the value of the field fi written in the format named
+// by the `number:""` struct tag, in the form strconv parses. Only values from
+// files are written in the format: environment variables and overrides are
+// already in that form.
+func (s *readState) number(fi fieldInfo, format, val string) (string, error) {
+	f, ok := numberFormats[format]
+	if !ok {
+		return "", newError(s.path, fi.name, KindUnsupported,
+			fmt.Errorf("unknown number format '%v'", format))
+	}
+
+	t := fi.f.Type
+	if l, ok := reflect.Zero(t).Interface().(lazyType); ok {
+		t = l.valueType()
+	}
+
+	whole := false
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		whole = true
+	case reflect.Float32, reflect.Float64:
+	default:
+		return "", newError(s.path, fi.name, KindUnsupported,
+			fmt.Errorf("the number tag needs an integer or float field, not %v", fi.f.Type))
+	}
+
+	if s.sources[fi.name].Layer != LayerFile {
+		return val, nil
+	}
+
+	n, ok := f.normalize(val, whole)
+	if !ok {
+		kind := "a number"
+		if whole {
+			kind = "a whole number"
+		}
+		return "", invalidError(s.path, fi.name,
+			fmt.Errorf("'%v' isn't %v written like %v", val, kind, f.example))
+	}
+	return n, nil
+}
+
+// formatNumber returns val, the value of the field fi as written by
+// formatValue, in the format named by its `number:""` struct tag, so that it
+// reads back the same. Only floats, and Lazy floats, are changed, since other
+// values are written the same way in every format.
+func formatNumber(fi fieldInfo, val string) string {
+	f, ok := numberFormats[fi.f.Tag.Get("number")]
+	if !ok {
+		return val
+	}
+
+	switch fi.v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		// The format doesn't allow exponents, which formatValue may use.
+		val = strconv.FormatFloat(fi.v.Float(), 'f', -1, fi.v.Type().Bits())
+	default:
+		l, ok := fi.v.Interface().(lazyType)
+		if !ok || (l.valueType().Kind() != reflect.Float32 && l.valueType().Kind() != reflect.Float64) {
+			return val
+		}
+	}
+	return strings.Replace(val, ".", f.decimal, 1)
+}
+
+// normalize returns val, a number written in f, in the form strconv parses.
+// It reports false if val isn't a number in f, or has a fraction and whole is
+// set.
+func (f numberFormat) normalize(val string, whole bool) (string, bool) {
+	sign := ""
+	if strings.HasPrefix(val, "-") || strings.HasPrefix(val, "+") {
+		sign, val = val[:1], val[1:]
+	}
+
+	intPart, frac, hasFrac := strings.Cut(val, f.decimal)
+	if hasFrac && (whole || !isDigits(frac)) {
+		return "", false
+	}
+
+	groups := []string{""}
+	for _, r := range intPart {
+		if strings.ContainsRune(f.groups, r) {
+			groups = append(groups, "")
+			continue
+		}
+		groups[len(groups)-1] += string(r)
+	}
+
+	for i, group := range groups {
+		if !isDigits(group) {
+			return "", false
+		}
+
+		// Only the first group may be short, and only if there are
+		// separators at all.
+		if len(groups) > 1 && (i == 0 && len(group) > 3 || i > 0 && len(group) != 3) {
+			return "", false
+		}
+	}
+
+	n := sign + strings.Join(groups, "")
+	if hasFrac {
+		n += "." + frac
+	}
+	return n, true
+}
+
+// isDigits reports whether s is made of one or more decimal digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package config_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestNumberTag(t *testing.T) {
+	var conf struct {
+		Ratio  float64 `config:"ratio" number:"comma"`
+		Limit  int     `config:"limit" number:"comma"`
+		Budget float64 `config:"budget" number:"point"`
+		Size   uint    `config:"size" number:"comma"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	ratio = 1,5
+	limit = 1.234.567
+	budget = "-1,234.25"
+	size = "10 000"
+	`), &conf, config.WithPrecedence(config.LayerFile))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Ratio != 1.5 || conf.Limit != 1234567 || conf.Budget != -1234.25 || conf.Size != 10000 {
+		t.Fatalf("expected 1.5, 1234567, -1234.25 and 10000, found %+v", conf)
+	}
+
+	tests := []string{"1.5", "12.34,5", "1,5,5", ",5", "1,", "1e3"}
+	for _, input := range tests {
+		var conf struct {
+			Ratio float64 `config:"ratio" number:"comma"`
+		}
+		err := config.Read("<input>", strings.NewReader("ratio = "+input), &conf,
+			config.WithPrecedence(config.LayerFile))
+		errs := config.Errors(err)
+		if len(errs) != 1 || errs[0].Kind != config.KindInvalid || !strings.Contains(err.Error(), "1.234,5") {
+			t.Fatalf(`expected "%v" to be invalid, found "%v"`, input, err)
+		}
+	}
+
+	var whole struct {
+		Limit int `config:"limit" number:"comma"`
+	}
+	err = config.Read("<input>", strings.NewReader("limit = 1,5"), &whole,
+		config.WithPrecedence(config.LayerFile))
+	if err == nil || !strings.Contains(err.Error(), "isn't a whole number") {
+		t.Fatalf("expected 1,5 not to be a whole number, found %v", err)
+	}
+
+	var unsupported struct {
+		Name string `config:"name" number:"comma"`
+	}
+	err = config.Read("<input>", strings.NewReader("name = 1,5"), &unsupported,
+		config.WithPrecedence(config.LayerFile))
+	if errs := config.Errors(err); len(errs) != 1 || errs[0].Kind != config.KindUnsupported {
+		t.Fatalf("expected the number tag on a string to be unsupported, found %v", err)
+	}
+}
+
+func TestNumberTagRoundTrip(t *testing.T) {
+	type numbers struct {
+		Ratio float64 `config:"ratio" number:"comma"`
+		Large float64 `config:"large" number:"comma"`
+		Limit int     `config:"limit" number:"comma"`
+	}
+	conf := numbers{Ratio: 1.5, Large: 1e21, Limit: 1000}
+	var b bytes.Buffer
+	if err := config.Write(&b, &conf); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if !strings.Contains(b.String(), "ratio = 1,5\n") {
+		t.Fatalf("expected ratio to be written with a comma, found:\n%v", b.String())
+	}
+
+	var read numbers
+	err := config.Read("<input>", &b, &read, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to read written config: %v", err)
+	}
+
+	if read != conf {
+		t.Fatalf("expected %+v, found %+v", conf, read)
+	}
+
+	err = config.Read("<input>", strings.NewReader("ratio = 2,5\nlarge = 1\nlimit = 1"), &read,
+		config.WithEnvironment(config.EnvMap{"RATIO": "0.25"}),
+		config.WithOverrides(config.Values{"large": "1.234"}))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	if read.Ratio != 0.25 || read.Large != 1.234 {
+		t.Fatalf("expected 0.25 and 1.234, found %v and %v", read.Ratio, read.Large)
+	}
+}
+
+func TestNumberTagLazy(t *testing.T) {
+	var conf struct {
+		Ratio config.Lazy[float64] `config:"ratio" number:"comma"`
+	}
+	err := config.Read("<input>", strings.NewReader("ratio = 1.000,5"), &conf,
+		config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	ratio, err := conf.Ratio.Get()
+	if err != nil || ratio != 1000.5 {
+		t.Fatalf("expected 1000.5, found %v: %v", ratio, err)
+	}
+	var b bytes.Buffer
+	if err := config.Write(&b, &conf); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if b.String() != "ratio = 1000,5\n" {
+		t.Fatalf(`expected "ratio = 1000,5", found "%v"`, b.String())
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"runtime"
+	"time"
+)
+
+// Option changes how a configuration is read.
+type Option func(*options)
+
+type options struct {
+	formatError     func(*Error) string
+	layers          []Layer
+	envPrefix       string
+	keyPattern      *regexp.Regexp
+	concurrency     int
+	overrides       Values
+	disallowUnknown bool
+	ignoreKeys      []string
+	allowedUnknown  []string
+	allowMissing    bool
+	report          *Report
+	wasSet          any
+	allowExport     bool
+	properties      bool
+	allowNull       bool
+	bareKeys        bool
+	references      bool
+	skipUnsupported bool
+	expressions     bool
+	policies        []Policy
+	readLimit       int64
+	readTimeout     time.Duration
+	env             Environment
+	schemaVersion   int
+	migrations      map[int]Migration
+	version         string
+	onDeprecated    func(Deprecation)
+	auditPath       string
+	verifyKeys      []ed25519.PublicKey
+	signature       []byte
+	// fsys holds the files read, if set with WithFS.
+	fsys         fs.FS
+	enforceFinal bool
+	finalKeys    []string
+	// section is the prefix of the options read, set by ReadSection.
+	section string
+	// effective is set to the effective values, for ReadValues.
+	effective *Values
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		layers:      []Layer{LayerFile, LayerEnv},
+		concurrency: runtime.GOMAXPROCS(0),
+		env:         osEnv{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithErrorFormatter makes every [Error] returned use f to produce its
+// message, for example to add hints on how to fix the problem or to translate
+// the messages. The fields of the Error are left as they are.
+func WithErrorFormatter(f func(*Error) string) Option {
+	return func(o *options) {
+		o.formatError = f
+	}
+}
+
+// finish applies the options which affect the errors returned to err.
+func (o *options) finish(err error) error {
+	if err == nil || o.formatError == nil {
+		return err
+	}
+
+	for _, e := range Errors(err) {
+		e.format = o.formatError
+	}
+	return err
+}
+
+// Layer is a source of option values.
+type Layer int
+
+const (
+	// LayerFile is the config file given to Read.
+	LayerFile Layer = iota
+	// LayerEnv is the environment variables named after each option.
+	LayerEnv
+	// LayerOverride is the values given to [config.WithOverrides]. It is
+	// always applied after every other layer, so it has no effect in
+	// WithPrecedence.
+	LayerOverride
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerFile:
+		return "file"
+	case LayerEnv:
+		return "env"
+	case LayerOverride:
+		return "override"
+	default:
+		return fmt.Sprintf("Layer(%d)", int(l))
+	}
+}
+
+// WithPrecedence sets which sources options are read from, from lowest to
+// highest precedence: a value from a later layer overrides one from an
+// earlier layer. The default is `WithPrecedence(LayerFile, LayerEnv)`.
+//
+// Layers which aren't given aren't read at all, so for example
+// `WithPrecedence(LayerEnv, LayerFile)` makes the file override the
+// environment, and `WithPrecedence(LayerFile)` ignores the environment.
+func WithPrecedence(layers ...Layer) Option {
+	return func(o *options) {
+		o.layers = layers
+	}
+}
+
+// WithOverrides sets options which override those from every layer, such as
+// values given on the command line. Like environment variables, they can't
+// set frozen options. Keys which aren't options are ignored.
+func WithOverrides(vals Values) Option {
+	return func(o *options) {
+		o.overrides = vals
+	}
+}
+
+// DisallowUnknownKeys makes it an error for the file to set an option which
+// the struct doesn't have, which usually means a typo. With
+// [config.ReadSection], only keys within the section are checked, since the
+// rest of the file belongs to other sections.
+func DisallowUnknownKeys() Option {
+	return func(o *options) {
+		o.disallowUnknown = true
+	}
+}
+
+// IgnoreKeys makes [config.DisallowUnknownKeys] accept keys matching any of
+// patterns, in the syntax of path.Match, where `*` also matches dots. For
+// example, `IgnoreKeys("x-*")` leaves keys starting with `x-` for other tools
+// to use.
+func IgnoreKeys(patterns ...string) Option {
+	return func(o *options) {
+		o.ignoreKeys = append(o.ignoreKeys, patterns...)
+	}
+}
+
+// WithAllowedUnknown makes [config.DisallowUnknownKeys] accept keys starting
+// with any of prefixes, such as `x_` or `experimental_`, while still catching
+// typos in every other key. Unlike [config.IgnoreKeys], the prefixes are
+// matched as they are, so they may contain `*` or `[`.
+func WithAllowedUnknown(prefixes ...string) Option {
+	return func(o *options) {
+		o.allowedUnknown = append(o.allowedUnknown, prefixes...)
+	}
+}
+
+// AllowExport makes the parser ignore `export` at the start of a line, so
+// files which are also sourced by a shell, like `export PORT=8080`, can be
+// read as they are. A key named `export` can still be set.
+func AllowExport() Option {
+	return func(o *options) {
+		o.allowExport = true
+	}
+}
+
+// AllowNull makes an unquoted `null` or `~` value unset an option instead of
+// setting it to that text, so a file can remove a value set by an earlier
+// file. When reading into a struct, an option set to null takes its zero
+// value, and counts as missing if it's required, unless a later layer sets it
+// again. Parse and ParseFiles leave such options out of the Values returned.
+// A quoted "null" is still the text null.
+func AllowNull() Option {
+	return func(o *options) {
+		o.allowNull = true
+	}
+}
+
+// AllowBareKeys makes a key on its own line, without `=` or a value, set the
+// key to true, so `verbose` is the same as `verbose = true`.
+func AllowBareKeys() Option {
+	return func(o *options) {
+		o.bareKeys = true
+	}
+}
+
+// AllowReferences makes an unquoted value starting with `*` copy a block of
+// options set earlier in the same file, so repeated settings only have to be
+// written once. `db.replica = *db.primary` sets `db.replica.host` to the value
+// of `db.primary.host`, and so on for every option nested in `db.primary`.
+// Options set after the reference override the copied values as usual, so
+// `db.replica.host` can follow it to change only the host. It is a syntax
+// error to refer to a block which has no options. A quoted value starting
+// with `*` is still text.
+func AllowReferences() Option {
+	return func(o *options) {
+		o.references = true
+	}
+}
+
+// AllowUnsupported makes an option whose field has a type the package can't
+// parse leave the field as it is instead of failing the read, so a large
+// struct can be read before all of its fields are supported. The errors of
+// kind KindUnsupported which would have been returned are listed in the
+// Unsupported field of the [config.Report] instead, if there is one. Only the
+// field's type is excused: an option which fails to parse for any other
+// reason is still an error.
+func AllowUnsupported() Option {
+	return func(o *options) {
+		o.skipUnsupported = true
+	}
+}
+
+// keyPattern matches keys made of identifiers separated by dots.
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*(\.[A-Za-z_][A-Za-z0-9_-]*)*$`)
+
+// StrictKeys makes it a syntax error for a key to be anything other than
+// identifiers (letters, digits, `_` and `-`, not starting with a digit or
+// `-`) separated by dots. Without it, a line like `foo bar = 1` sets the key
+// `foo bar`, which no field can have.
+func StrictKeys() Option {
+	return WithKeyPattern(keyPattern)
+}
+
+// WithKeyPattern makes it a syntax error for a key not to match re.
+func WithKeyPattern(re *regexp.Regexp) Option {
+	return func(o *options) {
+		o.keyPattern = re
+	}
+}
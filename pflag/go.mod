@@ -0,0 +1,15 @@
+module go.eldidi.org/config/pflag
+
+go 1.23.3
+
+require (
+	github.com/spf13/pflag v1.0.10
+	go.eldidi.org/config v0.0.0-20261016095914-71303e05ca8c
+)
+
+// The config module has no tagged release yet, so pflag requires the commit
+// which adds config.Fields and config.WithOverrides by its pseudo-version,
+// until a tag exists. The replace lets the module be built against the
+// config module in this repository; it's ignored when pflag is used as a
+// dependency, where the require above applies.
+replace go.eldidi.org/config => ../
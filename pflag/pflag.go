@@ -0,0 +1,130 @@
+// package pflag registers the options of a struct as flags on a
+// github.com/spf13/pflag FlagSet, as used by cobra, so that flags given on
+// the command line override the config file and environment:
+//
+//	var conf Config
+//	pflag.Register(cmd.Flags(), &conf)
+//	...
+//	err := config.ReadFile(path, &conf, config.WithOverrides(pflag.Overrides(cmd.Flags())))
+//
+// It lives in its own module so that the config module doesn't depend on
+// pflag.
+package pflag
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	spflag "github.com/spf13/pflag"
+	"go.eldidi.org/config"
+)
+
+// annotation is the flag annotation holding the name of the option a flag
+// sets.
+const annotation = "config-key"
+
+// Register adds a flag to fs for each option of the struct obj points to. A
+// flag is named after its option with `_` replaced by `-`, and its usage is
+// the field's `comment:""` struct tag. A `short:""` struct tag gives the flag
+// a one-letter shorthand. Frozen options can only be set from the file, so
+// they get no flag.
+//
+// Flags have no default, since the value comes from the config when the flag
+// isn't given. Boolean options can be given without a value to mean true.
+//
+// Register returns an error, and adds no flags, if a `short:""` tag is longer
+// than one letter, or if a flag's name or shorthand is already taken in fs or
+// by another option.
+func Register(fs *spflag.FlagSet, obj any) error {
+	fields, err := config.Fields(obj)
+	if err != nil {
+		return err
+	}
+
+	type flagInfo struct {
+		field config.Field
+		name  string
+		short string
+	}
+	var flags []flagInfo
+	names := map[string]string{}
+	shorts := map[string]string{}
+	for _, f := range fields {
+		if f.Frozen {
+			continue
+		}
+
+		name := strings.ReplaceAll(f.Name, "_", "-")
+		short := f.Tag.Get("short")
+		if len(short) > 1 {
+			return fmt.Errorf("pflag: option %v: shorthand %q is more than one letter", f.Name, short)
+		}
+
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("pflag: options %v and %v both have the flag --%v", other, f.Name, name)
+		} else if fs.Lookup(name) != nil {
+			return fmt.Errorf("pflag: option %v: flag --%v is already defined", f.Name, name)
+		}
+		names[name] = f.Name
+
+		if short != "" {
+			if other, ok := shorts[short]; ok {
+				return fmt.Errorf("pflag: options %v and %v both have the shorthand -%v", other, f.Name, short)
+			} else if fs.ShorthandLookup(short) != nil {
+				return fmt.Errorf("pflag: option %v: shorthand -%v is already defined", f.Name, short)
+			}
+			shorts[short] = f.Name
+		}
+
+		flags = append(flags, flagInfo{f, name, short})
+	}
+
+	for _, f := range flags {
+		value := &stringValue{typ: "string"}
+		if f.field.Type.Kind() == reflect.Bool {
+			value.typ = "bool"
+		}
+
+		flag := fs.VarPF(value, f.name, f.short, f.field.Tag.Get("comment"))
+		if value.typ == "bool" {
+			flag.NoOptDefVal = "true"
+		}
+		if err := fs.SetAnnotation(f.name, annotation, []string{f.field.Name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Overrides returns the values of the flags in fs registered by Register
+// which were given on the command line, for use with [config.WithOverrides].
+func Overrides(fs *spflag.FlagSet) config.Values {
+	vals := config.Values{}
+	fs.Visit(func(f *spflag.Flag) {
+		if key, ok := f.Annotations[annotation]; ok {
+			vals[key[0]] = f.Value.String()
+		}
+	})
+	return vals
+}
+
+// stringValue holds the text of a flag, which is converted when the config is
+// read so that errors refer to the option.
+type stringValue struct {
+	val string
+	typ string
+}
+
+func (v *stringValue) String() string {
+	return v.val
+}
+
+func (v *stringValue) Set(val string) error {
+	v.val = val
+	return nil
+}
+
+func (v *stringValue) Type() string {
+	return v.typ
+}
@@ -0,0 +1,92 @@
+package pflag_test
+
+import (
+	"strings"
+	"testing"
+
+	spflag "github.com/spf13/pflag"
+	"go.eldidi.org/config"
+	"go.eldidi.org/config/pflag"
+)
+
+func TestOverrides(t *testing.T) {
+	var conf struct {
+		Port     int    `config:"listen_port" short:"p" comment:"The port to listen on."`
+		Debug    bool   `config:"debug,optional"`
+		Host     string `config:"host"`
+		AuditLog string `config:"audit_log,frozen"`
+	}
+
+	fs := spflag.NewFlagSet("test", spflag.ContinueOnError)
+	if err := pflag.Register(fs, &conf); err != nil {
+		t.Fatalf("failed to register flags: %v", err)
+	}
+
+	if fs.Lookup("audit-log") != nil {
+		t.Fatal("expected no flag for frozen option")
+	}
+
+	if err := fs.Parse([]string{"-p", "9090", "--debug"}); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+
+	err := config.Read("<input>", strings.NewReader(`
+	listen_port = 8080
+	host = localhost
+	audit_log = /var/log/audit.log
+	`), &conf, config.WithOverrides(pflag.Overrides(fs)))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Port != 9090 {
+		t.Fatalf("expected 9090, found %v", conf.Port)
+	}
+
+	if !conf.Debug {
+		t.Fatal("expected true, found false")
+	}
+
+	if conf.Host != "localhost" {
+		t.Fatalf(`expected "localhost", found "%v"`, conf.Host)
+	}
+}
+
+func TestRegisterConflicts(t *testing.T) {
+	var long struct {
+		Port int `short:"pt"`
+	}
+	fs := spflag.NewFlagSet("test", spflag.ContinueOnError)
+	if err := pflag.Register(fs, &long); err == nil {
+		t.Fatal("expected error for a long shorthand, found no error")
+	}
+
+	var taken struct {
+		Port  int `short:"p"`
+		Debug bool
+	}
+	fs = spflag.NewFlagSet("test", spflag.ContinueOnError)
+	fs.Bool("debug", false, "")
+	if err := pflag.Register(fs, &taken); err == nil {
+		t.Fatal("expected error for a flag already defined, found no error")
+	}
+
+	if fs.Lookup("port") != nil {
+		t.Fatal("expected no flags to be added after an error")
+	}
+
+	fs = spflag.NewFlagSet("test", spflag.ContinueOnError)
+	fs.StringP("profile", "p", "", "")
+	if err := pflag.Register(fs, &taken); err == nil {
+		t.Fatal("expected error for a shorthand already defined, found no error")
+	}
+
+	var dashes struct {
+		ListenPort int    `config:"listen_port"`
+		Listen     string `config:"listen-port"`
+	}
+	fs = spflag.NewFlagSet("test", spflag.ContinueOnError)
+	if err := pflag.Register(fs, &dashes); err == nil {
+		t.Fatal("expected error for two options with the same flag, found no error")
+	}
+}
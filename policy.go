@@ -0,0 +1,58 @@
+package config
+
+import (
+	"errors"
+	"sort"
+)
+
+// Policy is the interface implemented by checks which every option read has
+// to pass, such as organisation-wide rules that nothing listens on a public
+// address or that TLS is enabled, whatever struct the options are read into.
+// A policy engine can be wired in behind it.
+type Policy interface {
+	// Check returns an error if the option key mustn't have the value
+	// read from source.
+	Check(key, value string, source Source) error
+}
+
+// PolicyFunc is a function which implements [Policy].
+type PolicyFunc func(key, value string, source Source) error
+
+func (f PolicyFunc) Check(key, value string, source Source) error {
+	return f(key, value, source)
+}
+
+// WithPolicy makes reading check each option against every one of policies,
+// once the file, environment and overrides have been applied and before the
+// fields are set. This includes keys which the struct doesn't have. An option
+// rejected by a policy is reported as an [Error] of kind KindPolicy, and
+// nothing is set when any option is rejected.
+func WithPolicy(policies ...Policy) Option {
+	return func(o *options) {
+		o.policies = append(o.policies, policies...)
+	}
+}
+
+// checkPolicies returns an error for each option in s.vals rejected by one of
+// policies.
+func (s *readState) checkPolicies(policies []Policy) error {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(s.vals))
+	for key := range s.vals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		for _, p := range policies {
+			if err := p.Check(key, s.vals[key], s.sources[key]); err != nil {
+				errs = append(errs, newError(s.path, key, KindPolicy, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
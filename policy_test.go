@@ -0,0 +1,50 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestWithPolicy(t *testing.T) {
+	env := config.EnvMap{"POLICY_LISTEN": "0.0.0.0:8080"}
+
+	noPublic := config.PolicyFunc(func(key, value string, source config.Source) error {
+		if strings.HasPrefix(value, "0.0.0.0:") {
+			return errors.New(key + " from " + source.String() + " listens on a public address")
+		}
+		return nil
+	})
+	tlsRequired := config.PolicyFunc(func(key, value string, source config.Source) error {
+		if key == "policy_tls" && value != "true" {
+			return errors.New("TLS is required")
+		}
+		return nil
+	})
+
+	var conf struct {
+		Listen string `config:"policy_listen"`
+		TLS    bool   `config:"policy_tls"`
+	}
+	err := config.Read("<input>", strings.NewReader("policy_listen = localhost:8080\npolicy_tls = false"),
+		&conf, config.WithPolicy(noPublic, tlsRequired), config.WithEnvironment(env))
+	errs := config.Errors(err)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, found %v: %v", len(errs), err)
+	}
+
+	if errs[0].Key != "policy_listen" || errs[0].Kind != config.KindPolicy ||
+		!strings.Contains(errs[0].Error(), "env POLICY_LISTEN") {
+		t.Fatalf("expected policy_listen from the environment to be rejected, found %v", errs[0])
+	}
+
+	if errs[1].Key != "policy_tls" || errs[1].Kind != config.KindPolicy {
+		t.Fatalf("expected policy_tls to be rejected, found %v", errs[1])
+	}
+
+	if conf.Listen != "" {
+		t.Fatalf(`expected nothing to be set, found "%v"`, conf.Listen)
+	}
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Properties makes the parser read files in the format of Java's .properties
+// files instead, so the config of JVM services can be kept as it is. In that
+// format:
+//
+//   - lines starting with `#` or `!` are comments,
+//   - a key ends at the first unescaped `=`, `:` or whitespace,
+//   - a line ending in an unescaped backslash continues on the next line,
+//     whose leading whitespace is skipped,
+//   - a backslash escapes the next character, and `\t`, `\n`, `\r`, `\f` and
+//     `\uXXXX` stand for the characters they do in Java.
+//
+// Quotes have no special meaning, and `#` only starts a comment at the start
+// of a line.
+func Properties() Option {
+	return func(o *options) {
+		o.properties = true
+	}
+}
+
+func parseProperties(path string, r io.Reader, o *options) ([]Entry, error) {
+	var entries []Entry
+	s := bufio.NewScanner(r)
+	lineNo := 0
+	syntaxError := func(line int, err error) error {
+		return &Error{File: path, Line: line, Kind: KindSyntax, Err: err}
+	}
+
+	// comments holds the comments above the next entry.
+	var comments []string
+	for s.Scan() {
+		lineNo += 1
+		start := lineNo
+		line := strings.TrimLeft(s.Text(), " \t\f")
+		if line == "" {
+			comments = nil
+			continue
+		} else if line[0] == '#' || line[0] == '!' {
+			comments = append(comments, strings.TrimSpace(line[1:]))
+			continue
+		}
+
+		for continues(line) && s.Scan() {
+			lineNo += 1
+			line = line[:len(line)-1] + strings.TrimLeft(s.Text(), " \t\f")
+		}
+		if continues(line) {
+			line = line[:len(line)-1]
+		}
+
+		key, rest := splitProperty(line)
+		key, err := unescapeProperty(key)
+		if err != nil {
+			return nil, syntaxError(start, err)
+		}
+
+		val, err := unescapeProperty(rest)
+		if err != nil {
+			return nil, syntaxError(start, err)
+		}
+
+		if o.keyPattern != nil && !o.keyPattern.MatchString(key) {
+			return nil, &Error{
+				File: path,
+				Line: start,
+				Key:  key,
+				Kind: KindSyntax,
+				Err:  fmt.Errorf("invalid key '%v'", key),
+			}
+		}
+		entries = append(entries, Entry{Key: key, Value: val, Line: start, Comments: comments})
+		comments = nil
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, &Error{File: path, Kind: KindIO, Err: err}
+	}
+
+	return entries, nil
+}
+
+// continues reports whether line ends in an unescaped backslash.
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i -= 1 {
+		n += 1
+	}
+	return n%2 == 1
+}
+
+// splitProperty splits line into its still escaped key and value.
+func splitProperty(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i += 1 {
+		if line[i] == '\\' {
+			i += 1
+			continue
+		}
+
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	key, rest := line[:end], strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+// unescapeProperty replaces the escape sequences in s.
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i += 1 {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i += 1
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			c, err := parseUnicodeEscape(s[i+1:])
+			if err != nil {
+				return "", err
+			}
+			i += 4
+
+			// Java writes characters outside the BMP as a pair of
+			// surrogates, each escaped on its own.
+			if utf16.IsSurrogate(c) && strings.HasPrefix(s[i+1:], `\u`) {
+				if low, err := parseUnicodeEscape(s[i+3:]); err == nil {
+					if r := utf16.DecodeRune(c, low); r != unicode.ReplacementChar {
+						c = r
+						i += 6
+					}
+				}
+			}
+			b.WriteRune(c)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// parseUnicodeEscape parses the four hex digits at the start of s, which
+// follow `\u`.
+func parseUnicodeEscape(s string) (rune, error) {
+	if len(s) < 4 {
+		return 0, errors.New(`malformed \uXXXX escape`)
+	}
+
+	c, err := strconv.ParseUint(s[:4], 16, 16)
+	if err != nil {
+		return 0, errors.New(`malformed \uXXXX escape`)
+	}
+	return rune(c), nil
+}
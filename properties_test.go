@@ -0,0 +1,48 @@
+package config_test
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestProperties(t *testing.T) {
+	vals, err := config.Parse("app.properties", strings.NewReader(`
+# comment
+! also a comment
+server.port=8080
+server.host : localhost
+greeting Hello, \
+         World
+path = C:\\Temp\\app
+unicode = caf\u00e9
+emoji = \uD83D\uDE00 smile
+key\ with\ spaces = yes
+empty
+`), config.Properties())
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	expected := config.Values{
+		"server.port":     "8080",
+		"server.host":     "localhost",
+		"greeting":        "Hello, World",
+		"path":            `C:\Temp\app`,
+		"unicode":         "café",
+		"emoji":           "\U0001F600 smile",
+		"key with spaces": "yes",
+		"empty":           "",
+	}
+	if !maps.Equal(vals, expected) {
+		t.Fatalf("expected %v, found %v", expected, vals)
+	}
+
+	_, err = config.Parse("app.properties", strings.NewReader("\nbad = \\u00zz"), config.Properties())
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Line != 2 || errs[0].Kind != config.KindSyntax {
+		t.Fatalf("expected a syntax error on line 2, found %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+// ReadOnly holds a config which can't be changed once it's been read. Get
+// returns a copy, so assigning to its fields doesn't affect anyone else using
+// the config. Slices, maps and pointers in the copy still refer to the shared
+// values, and must be treated as read-only.
+//
+// A ReadOnly is safe for concurrent use, and can be copied.
+type ReadOnly[T any] struct {
+	v *T
+}
+
+// NewReadOnly returns a ReadOnly holding a copy of cfg.
+func NewReadOnly[T any](cfg T) ReadOnly[T] {
+	return ReadOnly[T]{v: &cfg}
+}
+
+// Get returns a copy of the config. It returns the zero value of T if r was
+// not created by NewReadOnly.
+func (r ReadOnly[T]) Get() T {
+	if r.v == nil {
+		var zero T
+		return zero
+	}
+	return *r.v
+}
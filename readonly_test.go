@@ -0,0 +1,30 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestReadOnly(t *testing.T) {
+	var conf struct {
+		Port int
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	port = 8080
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	ro := config.NewReadOnly(conf)
+	conf.Port = 1
+
+	c := ro.Get()
+	c.Port = 2
+
+	if ro.Get().Port != 8080 {
+		t.Fatalf("expected 8080, found %v", ro.Get().Port)
+	}
+}
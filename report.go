@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Report records where the options read by [config.Read] and the functions
+// like it came from, when given to [config.WithReport].
+type Report struct {
+	// Files holds the config files read, in the order they were merged.
+	Files []string
+	// Sources holds where the value of each option set came from, by key.
+	// It includes keys in the files which aren't options of the struct.
+	Sources map[string]Source
+	// Values holds the effective value of each option, as returned by
+	// [config.ReadValues].
+	Values Values
+	// Skipped holds the fields of the struct which weren't read because
+	// they're unexported, so that a field which was meant to be an option
+	// doesn't go unnoticed.
+	Skipped []SkippedField
+	// Unsupported holds the errors for options whose fields have types which
+	// can't be parsed, which [config.AllowUnsupported] skips instead of
+	// returning.
+	Unsupported []*Error
+	// Deprecated holds the deprecated options which were set.
+	Deprecated []Deprecation
+	// Fingerprint is the [config.Fingerprint] of the struct after reading.
+	Fingerprint string
+	// Fields holds the options of the struct, as returned by
+	// [config.Fields].
+	Fields []Field
+	// ParseTime is the time spent reading and parsing the files, and
+	// BindTime the time spent setting the fields of the struct after that.
+	ParseTime time.Duration
+	BindTime  time.Duration
+	// FieldTimes holds the time spent converting the value of each option
+	// set, by key, for finding which options make startup slow.
+	FieldTimes map[string]time.Duration
+}
+
+// Source is where the value of an option came from.
+type Source struct {
+	Layer Layer
+	// Name is the path of the file for LayerFile, or the name of the
+	// environment variable for LayerEnv. It is empty for LayerOverride.
+	Name string
+}
+
+func (s Source) String() string {
+	if s.Name == "" {
+		return s.Layer.String()
+	}
+	return s.Layer.String() + " " + s.Name
+}
+
+// WithReport makes reading fill in r with where each option came from. r is
+// filled in even if reading fails, with what was read up to that point.
+func WithReport(r *Report) Option {
+	return func(o *options) {
+		o.report = r
+	}
+}
+
+// fillReport sets r to what s has read into fields of the struct v.
+func (s *readState) fillReport(r *Report, v reflect.Value, fields []fieldInfo) {
+	t := v.Type()
+	r.Files = s.files
+	r.Sources = s.sources
+	// The values are copied, since fields such as Lazy keep s.vals to
+	// convert later, perhaps concurrently with changes to the report.
+	r.Values = maps.Clone(s.vals)
+	r.Skipped = skippedFields(t, "", nil)
+	r.Fields = exportFields(fields)
+	r.Deprecated = s.deprecations
+	r.Unsupported = s.unsupported
+	// An option which can't be formatted leaves the fingerprint empty.
+	r.Fingerprint, _ = Fingerprint(v.Addr().Interface())
+	r.ParseTime = s.parseTime
+	r.BindTime = s.bindTime
+	r.FieldTimes = s.timings
+}
+
+// Summary is an overview of a read, as returned by [Report.Summary]. It
+// implements slog.LogValuer, so a standard line can be logged at startup with
+// `slog.Info("config loaded", "config", report.Summary())`.
+type Summary struct {
+	// Files holds the config files read.
+	Files []string
+	// Keys is the number of options set, and Env and Overrides the number
+	// of those set by environment variables and overrides.
+	Keys      int
+	Env       int
+	Overrides int
+	// Defaults is the number of options which weren't set, and kept the
+	// value the struct had.
+	Defaults    int
+	Fingerprint string
+}
+
+// Summary returns an overview of r.
+func (r *Report) Summary() Summary {
+	s := Summary{Files: r.Files, Fingerprint: r.Fingerprint}
+	for _, f := range r.Fields {
+		source, ok := r.Sources[f.Name]
+		switch {
+		case !ok:
+			s.Defaults += 1
+			continue
+		case source.Layer == LayerEnv:
+			s.Env += 1
+		case source.Layer == LayerOverride:
+			s.Overrides += 1
+		}
+		s.Keys += 1
+	}
+	return s
+}
+
+func (s Summary) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("files", strings.Join(s.Files, ",")),
+		slog.Int("keys", s.Keys),
+		slog.Int("env", s.Env),
+		slog.Int("overrides", s.Overrides),
+		slog.Int("defaults", s.Defaults),
+		slog.String("fingerprint", s.Fingerprint),
+	)
+}
+
+// Sdump returns a table of the options in r for debugging, one per line, with
+// the key, the effective value, the type of the field and where the value came
+// from. The values of secret options are masked. Options which weren't set
+// are listed with the source `default`, since they kept the value the struct
+// had. Keys which aren't options of the struct are listed at the end, with
+// the type `unknown`.
+func Sdump(r *Report) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tTYPE\tSOURCE")
+
+	known := map[string]bool{}
+	for _, f := range r.Fields {
+		known[f.Name] = true
+		val, ok := r.Values[f.Name]
+		source := "default"
+		if ok {
+			source = r.Sources[f.Name].String()
+		}
+
+		switch {
+		case !ok:
+			val = "-"
+		case f.Secret:
+			val = "******"
+		default:
+			val = strconv.Quote(val)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", f.Name, val, f.Type, source)
+	}
+
+	var unknown []string
+	for key := range r.Values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		fmt.Fprintf(w, "%v\t%v\tunknown\t%v\n", key, strconv.Quote(r.Values[key]), r.Sources[key])
+	}
+
+	w.Flush()
+	return b.String()
+}
+
+// FirstOf returns the first of paths which exists, for programs which look
+// for their config in several places, such as a per-user file before a
+// system-wide one. If none of them exist, it returns an error of kind KindIO
+// referring to all of them, which matches fs.ErrNotExist.
+func FirstOf(paths ...string) (string, error) {
+	return FirstOfFS(nil, paths...)
+}
+
+// FirstOfFS is like [config.FirstOf], but looks for the files in fsys, as
+// given to [config.WithFS]. If fsys is nil, it looks on the host like
+// FirstOf.
+func FirstOfFS(fsys fs.FS, paths ...string) (string, error) {
+	for _, path := range paths {
+		var err error
+		if fsys != nil {
+			_, err = fs.Stat(fsys, path)
+		} else {
+			_, err = os.Stat(path)
+		}
+		if err == nil {
+			return path, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", &Error{File: path, Kind: KindIO, Err: err}
+		}
+	}
+
+	return "", &Error{
+		File: strings.Join(paths, ", "),
+		Kind: KindIO,
+		Err:  fmt.Errorf("none of the files exist: %w", fs.ErrNotExist),
+	}
+}
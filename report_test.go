@@ -0,0 +1,209 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go.eldidi.org/config"
+)
+
+func TestReport(t *testing.T) {
+	env := config.EnvMap{"REPORT_PORT": "9090"}
+	paths := writeFiles(t, "report_host = localhost\nreport_port = 80", "report_host = example.com")
+
+	var conf struct {
+		Host  string `config:"report_host"`
+		Port  int    `config:"report_port"`
+		Debug bool   `config:"report_debug"`
+	}
+	var report config.Report
+	err := config.ReadFiles(paths, &conf, config.WithReport(&report),
+		config.WithOverrides(config.Values{"report_debug": "true"}), config.WithEnvironment(env))
+	if err != nil {
+		t.Fatalf("failed to read files into struct: %v", err)
+	}
+
+	if len(report.Files) != 2 || report.Files[1] != paths[1] {
+		t.Fatalf("expected %v, found %v", paths, report.Files)
+	}
+
+	expected := map[string]string{
+		"report_host":  "file " + paths[1],
+		"report_port":  "env REPORT_PORT",
+		"report_debug": "override",
+	}
+	for key, source := range expected {
+		if report.Sources[key].String() != source {
+			t.Fatalf(`expected "%v" for %v, found "%v"`, source, key, report.Sources[key])
+		}
+	}
+}
+
+func TestFirstOf(t *testing.T) {
+	paths := writeFiles(t, "a = 1", "b = 2")
+	missing := filepath.Join(t.TempDir(), "missing.conf")
+
+	path, err := config.FirstOf(missing, paths[1], paths[0])
+	if err != nil {
+		t.Fatalf("failed to find file: %v", err)
+	}
+
+	if path != paths[1] {
+		t.Fatalf(`expected "%v", found "%v"`, paths[1], path)
+	}
+
+	_, err = config.FirstOf(missing)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist, found %v", err)
+	}
+}
+
+func TestFirstOfFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/app.conf": {Data: []byte("a = 1")},
+	}
+
+	path, err := config.FirstOfFS(fsys, "home/app.conf", "etc/app.conf")
+	if err != nil {
+		t.Fatalf("failed to find file: %v", err)
+	}
+
+	if path != "etc/app.conf" {
+		t.Fatalf(`expected "etc/app.conf", found "%v"`, path)
+	}
+
+	_, err = config.FirstOfFS(fsys, "home/app.conf")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist, found %v", err)
+	}
+}
+
+type skippedServer struct {
+	Host string
+	port int `config:"port"`
+}
+
+func TestReportSkipped(t *testing.T) {
+	var conf struct {
+		Server  skippedServer
+		timeout int
+	}
+	var report config.Report
+	err := config.Read("<input>", strings.NewReader(`
+	server.host = localhost
+	`), &conf, config.WithReport(&report))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if len(report.Skipped) != 2 {
+		t.Fatalf("expected 2 skipped fields, found %v", report.Skipped)
+	}
+
+	if report.Skipped[0].Field != "Server.port" || !strings.Contains(report.Skipped[0].Reason, "FieldSetter") {
+		t.Fatalf("expected Server.port to be skipped for lacking a FieldSetter, found %+v", report.Skipped[0])
+	}
+
+	if report.Skipped[1].Field != "timeout" {
+		t.Fatalf("expected timeout to be skipped, found %+v", report.Skipped[1])
+	}
+}
+
+func TestReportTimes(t *testing.T) {
+	var conf struct {
+		Host string `config:"host"`
+		Port int    `config:"port,optional"`
+	}
+	var report config.Report
+	err := config.Read("<input>", strings.NewReader(`
+	host = localhost
+	`), &conf, config.WithReport(&report), config.WithPrecedence(config.LayerFile))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if _, ok := report.FieldTimes["host"]; !ok || len(report.FieldTimes) != 1 {
+		t.Fatalf("expected a time for host only, found %v", report.FieldTimes)
+	}
+
+	if report.ParseTime < 0 || report.BindTime < report.FieldTimes["host"] {
+		t.Fatalf("expected bind time of at least %v, found %v", report.FieldTimes["host"], report.BindTime)
+	}
+}
+
+func TestSdump(t *testing.T) {
+	env := config.EnvMap{"SDUMP_PORT": "9090"}
+	paths := writeFiles(t, "sdump_host = localhost\nsdump_password = hunter2\nsdump_extra = 1")
+
+	var conf struct {
+		Host     string `config:"sdump_host"`
+		Port     int    `config:"sdump_port"`
+		Debug    bool   `config:"sdump_debug,optional"`
+		Password string `config:"sdump_password,secret"`
+	}
+	var report config.Report
+	if err := config.ReadFiles(paths, &conf, config.WithReport(&report),
+		config.WithEnvironment(env)); err != nil {
+		t.Fatalf("failed to read files into struct: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"KEY             VALUE        TYPE     SOURCE",
+		"sdump_host      \"localhost\"  string   file " + paths[0],
+		"sdump_port      \"9090\"       int      env SDUMP_PORT",
+		"sdump_debug     -            bool     default",
+		"sdump_password  ******       string   file " + paths[0],
+		"sdump_extra     \"1\"          unknown  file " + paths[0],
+		"",
+	}, "\n")
+	if dump := config.Sdump(&report); dump != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, dump)
+	}
+}
+
+func TestReportSummary(t *testing.T) {
+	env := config.EnvMap{"SUMMARY_PORT": "9090"}
+	paths := writeFiles(t, "summary_host = localhost\nsummary_port = 80")
+
+	var conf struct {
+		Host  string `config:"summary_host"`
+		Port  int    `config:"summary_port"`
+		Debug bool   `config:"summary_debug,optional"`
+		Level string `config:"summary_level,optional"`
+		Name  string `config:"summary_name"`
+	}
+	var report config.Report
+	err := config.ReadFiles(paths, &conf, config.WithReport(&report),
+		config.WithOverrides(config.Values{"summary_name": "app"}), config.WithEnvironment(env))
+	if err != nil {
+		t.Fatalf("failed to read files into struct: %v", err)
+	}
+
+	fingerprint, err := config.Fingerprint(&conf)
+	if err != nil {
+		t.Fatalf("failed to fingerprint config: %v", err)
+	}
+
+	var b strings.Builder
+	logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("config loaded", "config", report.Summary())
+
+	expected := fmt.Sprintf("level=INFO msg=\"config loaded\" config.files=%v config.keys=3 config.env=1 "+
+		"config.overrides=1 config.defaults=2 config.fingerprint=%v\n", paths[0], fingerprint)
+	if b.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, b.String())
+	}
+}
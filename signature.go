@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// VerifySignatures makes reading refuse a config file unless it has a valid
+// ed25519 signature by one of keys, so that configs distributed to a fleet
+// can't be tampered with. The signature of a file read by [config.ReadFile]
+// and the functions like it is read from the file of the same name with
+// `.sig` added, such as `app.conf.sig`; for [config.Read] and the other
+// functions given a reader, it's given with [config.WithSignature]. A
+// signature is the 64 bytes of an ed25519 signature of the whole file, either
+// as they are or in base64.
+//
+// The whole file is read and checked before any of it is parsed. A missing or
+// invalid signature, or a key which isn't ed25519.PublicKeySize bytes long, is
+// an error of kind KindSignature.
+func VerifySignatures(keys ...ed25519.PublicKey) Option {
+	return func(o *options) {
+		o.verifyKeys = append(o.verifyKeys, keys...)
+	}
+}
+
+// WithSignature gives the signature of the config read from a reader, for
+// [config.VerifySignatures].
+func WithSignature(sig []byte) Option {
+	return func(o *options) {
+		o.signature = sig
+	}
+}
+
+// input returns r wrapped to enforce the read limit and timeout in o, after
+// checking its signature if o has keys to verify it with.
+func input(path string, r io.Reader, o *options) (io.Reader, error) {
+	r = guardReader(r, o)
+	if len(o.verifyKeys) == 0 {
+		return r, nil
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &Error{File: path, Kind: KindIO, Err: err}
+	}
+
+	if err := verifySignature(data, o.signature, o.verifyKeys); err != nil {
+		return nil, &Error{File: path, Kind: KindSignature, Err: err}
+	}
+	return bytes.NewReader(data), nil
+}
+
+// verifySignature returns an error unless sig is a signature of data by one
+// of keys.
+func verifySignature(data, sig []byte, keys []ed25519.PublicKey) error {
+	for _, key := range keys {
+		// ed25519.Verify panics on a key of the wrong length.
+		if len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("public key is %v bytes, not %v", len(key), ed25519.PublicKeySize)
+		}
+	}
+
+	if len(sig) == 0 {
+		return errors.New("no signature")
+	}
+
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+		if err != nil || len(decoded) != ed25519.SignatureSize {
+			return fmt.Errorf("signature isn't %v bytes or base64", ed25519.SignatureSize)
+		}
+		sig = decoded
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, data, sig) {
+			return nil
+		}
+	}
+	return errors.New("signature doesn't match")
+}
+
+// fileSignature returns o with the signature of the file at path, if o has
+// keys to verify it with.
+func fileSignature(path string, o *options) (*options, error) {
+	if len(o.verifyKeys) == 0 {
+		return o, nil
+	}
+
+	f, err := o.open(path + ".sig")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &Error{File: path, Kind: KindSignature, Err: errors.New("no signature")}
+	} else if err != nil {
+		return nil, &Error{File: path, Kind: KindIO, Err: err}
+	}
+	defer f.Close()
+
+	signed := *o
+	if signed.signature, err = io.ReadAll(f); err != nil {
+		return nil, &Error{File: path, Kind: KindIO, Err: err}
+	}
+	return &signed, nil
+}
@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestVerifySignatures(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "port = 8080\n"
+	sig := ed25519.Sign(priv, []byte(content))
+	paths := writeFiles(t, content, content)
+	if err := os.WriteFile(paths[0]+".sig", []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var conf struct {
+		Port int
+	}
+	if err := config.ReadFile(paths[0], &conf, config.VerifySignatures(other, pub),
+		config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read signed file: %v", err)
+	}
+
+	err = config.ReadFiles(paths, &conf, config.VerifySignatures(pub),
+		config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].File != paths[1] || errs[0].Kind != config.KindSignature {
+		t.Fatalf("expected %v to have no signature, found %v", paths[1], err)
+	}
+
+	err = config.Read("<input>", strings.NewReader(content), &conf,
+		config.VerifySignatures(pub), config.WithSignature(sig), config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to read signed input: %v", err)
+	}
+
+	_, err = config.Parse("<input>", strings.NewReader("port = 9090\n"),
+		config.VerifySignatures(pub), config.WithSignature(sig))
+	var e *config.Error
+	if !errors.As(err, &e) || e.Kind != config.KindSignature {
+		t.Fatalf("expected the signature not to match, found %v", err)
+	}
+}
+
+func TestVerifySignaturesShortKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "port = 8080\n"
+	sig := ed25519.Sign(priv, []byte(content))
+	_, err = config.Parse("<input>", strings.NewReader(content),
+		config.VerifySignatures(ed25519.PublicKey("short"), pub), config.WithSignature(sig))
+	var e *config.Error
+	if !errors.As(err, &e) || e.Kind != config.KindSignature {
+		t.Fatalf("expected a signature error for a short key, found %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML described by [config.FormatTOML]. Keys
+// in a table are prefixed with the table's name and a dot, as the options of
+// a nested struct are.
+func parseTOML(path string, r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	s := bufio.NewScanner(r)
+	lineNo := 0
+	syntaxError := func(err error) error {
+		return &Error{File: path, Line: lineNo, Kind: KindSyntax, Err: err}
+	}
+
+	// table is the name of the table being read, and comments holds the
+	// comments above the next entry.
+	var table string
+	var comments []string
+	for s.Scan() {
+		lineNo += 1
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			comments = nil
+			continue
+		} else if line[0] == '#' {
+			comments = append(comments, strings.TrimSpace(line[1:]))
+			continue
+		}
+
+		if line[0] == '[' {
+			if strings.HasPrefix(line, "[[") {
+				return nil, syntaxError(errors.New("arrays of tables aren't supported"))
+			}
+
+			key, rest, err := parseTOMLKey(line[1:])
+			if err != nil {
+				return nil, syntaxError(err)
+			} else if !strings.HasPrefix(rest, "]") {
+				return nil, syntaxError(errors.New("expected ']' after table name"))
+			}
+
+			comment, err := tomlComment(rest[1:])
+			if err != nil {
+				return nil, syntaxError(err)
+			}
+
+			// The comments above a table describe it, so they're kept
+			// for its first entry.
+			table = key
+			if comment != "" {
+				comments = append(comments, comment)
+			}
+			continue
+		}
+
+		key, rest, err := parseTOMLKey(line)
+		if err != nil {
+			return nil, syntaxError(err)
+		} else if !strings.HasPrefix(rest, "=") {
+			return nil, syntaxError(fmt.Errorf("expected '=' after key '%v'", key))
+		}
+
+		val, rest, err := parseTOMLValue(strings.TrimLeft(rest[1:], " \t"))
+		if err != nil {
+			return nil, syntaxError(err)
+		}
+
+		comment, err := tomlComment(rest)
+		if err != nil {
+			return nil, syntaxError(err)
+		}
+
+		e := Entry{Key: joinName(table, key), Value: val, Line: lineNo, Comments: comments}
+		if comment != "" {
+			e.Comments = append(e.Comments, comment)
+		}
+		entries = append(entries, e)
+		comments = nil
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, &Error{File: path, Kind: KindIO, Err: err}
+	}
+
+	return entries, nil
+}
+
+// parseTOMLKey parses the bare, quoted or dotted key at the start of s,
+// returning it with its parts joined by dots, and the rest of s after any
+// whitespace.
+func parseTOMLKey(s string) (string, string, error) {
+	var parts []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		var part string
+		var err error
+		switch {
+		case strings.HasPrefix(s, `"`):
+			part, s, err = parseTOMLBasic(s)
+		case strings.HasPrefix(s, "'"):
+			part, s, err = parseTOMLLiteral(s)
+		default:
+			end := strings.IndexFunc(s, func(c rune) bool { return !isTOMLBare(c) })
+			if end == -1 {
+				end = len(s)
+			}
+			if end == 0 {
+				return "", "", errors.New("expected a key")
+			}
+			part, s = s[:end], s[end:]
+		}
+		if err != nil {
+			return "", "", err
+		}
+
+		parts = append(parts, part)
+		s = strings.TrimLeft(s, " \t")
+		if !strings.HasPrefix(s, ".") {
+			return strings.Join(parts, "."), s, nil
+		}
+		s = s[1:]
+	}
+}
+
+// tomlBare matches the values other than strings in the TOML subset:
+// booleans, numbers and dates, which are kept as the text they're written as.
+// A date and time may be separated by a space.
+var tomlBare = regexp.MustCompile(
+	`^(?:\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:\d{2})?|[+-]?(?:inf|nan)|true|false|[0-9+-][0-9A-Za-z_+.:-]*)`,
+)
+
+// parseTOMLValue parses the value at the start of s, returning its text and
+// the rest of s.
+func parseTOMLValue(s string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''"):
+		return "", "", errors.New("multi-line strings aren't supported")
+	case strings.HasPrefix(s, `"`):
+		return parseTOMLBasic(s)
+	case strings.HasPrefix(s, "'"):
+		return parseTOMLLiteral(s)
+	case strings.HasPrefix(s, "["):
+		return "", "", errors.New("arrays aren't supported")
+	case strings.HasPrefix(s, "{"):
+		return "", "", errors.New("inline tables aren't supported")
+	}
+
+	val := tomlBare.FindString(s)
+	if val == "" {
+		return "", "", errors.New("expected a value")
+	}
+	return val, s[len(val):], nil
+}
+
+// parseTOMLBasic parses the double quoted string at the start of s, replacing
+// its escape sequences, and returns it with the rest of s.
+func parseTOMLBasic(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i += 1 {
+		switch s[i] {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+		default:
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i += 1
+		if i == len(s) {
+			break
+		}
+
+		switch s[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(s[i])
+		case 'u', 'U':
+			n := 4
+			if s[i] == 'U' {
+				n = 8
+			}
+			if i+n >= len(s) {
+				return "", "", fmt.Errorf(`malformed \%c escape`, s[i])
+			}
+
+			c, err := strconv.ParseUint(s[i+1:i+n+1], 16, 32)
+			if err != nil {
+				return "", "", fmt.Errorf(`malformed \%c escape`, s[i])
+			}
+			b.WriteRune(rune(c))
+			i += n
+		default:
+			return "", "", fmt.Errorf(`unknown escape '\%c'`, s[i])
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+// parseTOMLLiteral parses the single quoted string at the start of s, and
+// returns it with the rest of s.
+func parseTOMLLiteral(s string) (string, string, error) {
+	end := strings.IndexByte(s[1:], '\'')
+	if end == -1 {
+		return "", "", errors.New("unterminated string")
+	}
+	return s[1 : end+1], s[end+2:], nil
+}
+
+// tomlComment returns the text of the comment in rest, what follows a key or
+// value on its line, which must be nothing else.
+func tomlComment(rest string) (string, error) {
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return "", nil
+	} else if rest[0] != '#' {
+		return "", fmt.Errorf("unexpected '%v'", rest)
+	}
+	return strings.TrimSpace(rest[1:]), nil
+}
+
+// isTOMLBare reports whether c can appear in a bare key.
+func isTOMLBare(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// tomlPlain matches the values written without quotes: booleans and decimal
+// numbers, which read as themselves.
+var tomlPlain = regexp.MustCompile(`^(?:true|false|[+-]?(?:0|[1-9][0-9]*)(?:\.[0-9]+)?)$`)
+
+// tomlKey returns key as a dotted TOML key, quoting the parts which can't be
+// bare.
+func tomlKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if part == "" || strings.IndexFunc(part, func(c rune) bool { return !isTOMLBare(c) }) != -1 {
+			parts[i] = quoteTOML(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlValue returns val as a TOML value which parseTOMLValue reads back as
+// it is.
+func tomlValue(val string) string {
+	if tomlPlain.MatchString(val) {
+		return val
+	}
+	return quoteTOML(val)
+}
+
+// quoteTOML returns s as a double quoted TOML string.
+func quoteTOML(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range s {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case c == '\b':
+			b.WriteString(`\b`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\f':
+			b.WriteString(`\f`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"math/big"
+	"net/mail"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// builtinTypes holds the conversions for types from outside this package
+// which are supported even though they don't implement ValueParser.
+var builtinTypes = map[reflect.Type]func(string) (any, error){
+	reflect.TypeFor[*regexp.Regexp](): func(s string) (any, error) {
+		return regexp.Compile(s)
+	},
+	reflect.TypeFor[*time.Location](): func(s string) (any, error) {
+		return time.LoadLocation(s)
+	},
+	reflect.TypeFor[mail.Address](): func(s string) (any, error) {
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		return *addr, nil
+	},
+	reflect.TypeFor[*mail.Address](): func(s string) (any, error) {
+		return mail.ParseAddress(s)
+	},
+	reflect.TypeFor[[]mail.Address](): func(s string) (any, error) {
+		list, err := mail.ParseAddressList(s)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs := make([]mail.Address, len(list))
+		for i, addr := range list {
+			addrs[i] = *addr
+		}
+		return addrs, nil
+	},
+	reflect.TypeFor[[]*mail.Address](): func(s string) (any, error) {
+		return mail.ParseAddressList(s)
+	},
+	reflect.TypeFor[big.Int](): func(s string) (any, error) {
+		n, err := parseBigInt(s)
+		if err != nil {
+			return nil, err
+		}
+		return *n, nil
+	},
+	reflect.TypeFor[*big.Int](): func(s string) (any, error) {
+		return parseBigInt(s)
+	},
+	reflect.TypeFor[big.Float](): func(s string) (any, error) {
+		f, err := parseBigFloat(s)
+		if err != nil {
+			return nil, err
+		}
+		return *f, nil
+	},
+	reflect.TypeFor[*big.Float](): func(s string) (any, error) {
+		return parseBigFloat(s)
+	},
+}
+
+// builtinWriters holds the conversions back to text for the types in
+// builtinTypes which don't implement encoding.TextMarshaler.
+var builtinWriters = map[reflect.Type]func(any) string{
+	reflect.TypeFor[*regexp.Regexp](): func(x any) string {
+		return x.(*regexp.Regexp).String()
+	},
+	reflect.TypeFor[*time.Location](): func(x any) string {
+		return x.(*time.Location).String()
+	},
+	reflect.TypeFor[mail.Address](): func(x any) string {
+		addr := x.(mail.Address)
+		return addr.String()
+	},
+	reflect.TypeFor[*mail.Address](): func(x any) string {
+		return x.(*mail.Address).String()
+	},
+	reflect.TypeFor[[]mail.Address](): func(x any) string {
+		addrs := x.([]mail.Address)
+		list := make([]string, len(addrs))
+		for i := range addrs {
+			list[i] = addrs[i].String()
+		}
+		return strings.Join(list, ", ")
+	},
+	reflect.TypeFor[[]*mail.Address](): func(x any) string {
+		addrs := x.([]*mail.Address)
+		list := make([]string, len(addrs))
+		for i, addr := range addrs {
+			list[i] = addr.String()
+		}
+		return strings.Join(list, ", ")
+	},
+}
+
+// parseBigInt parses an integer of any size, accepting the same base prefixes
+// as the other integer types.
+func parseBigInt(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("'%v' is not a valid integer", s)
+	}
+	return n, nil
+}
+
+// parseBigFloat parses a floating point number with enough precision to keep
+// every digit given, rather than the 64 bits big.Float defaults to.
+func parseBigFloat(s string) (*big.Float, error) {
+	// Each decimal digit needs a little under 4 bits.
+	prec := uint(len(s)) * 4
+	if prec < 64 {
+		prec = 64
+	}
+
+	f, _, err := big.ParseFloat(s, 0, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Glob is a shell-style pattern, as understood by [path.Match]. The pattern is
+// checked when the config is read, so a malformed pattern is reported then
+// rather than on first use.
+type Glob string
+
+func (g *Glob) ParseConfigValue(s string) error {
+	if _, err := path.Match(s, ""); err != nil {
+		return fmt.Errorf("invalid glob pattern '%v': %w", s, err)
+	}
+
+	*g = Glob(s)
+	return nil
+}
+
+// Match reports whether name matches the pattern.
+func (g Glob) Match(name string) bool {
+	matched, _ := path.Match(string(g), name)
+	return matched
+}
+
+func (g Glob) String() string {
+	return string(g)
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Logging holds the options for setting up a [slog.Logger]. All of the
+// options are optional: by default, messages at level info and above are
+// written to standard error as text.
+type Logging struct {
+	Level  slog.Level `config:"log_level,optional"`
+	Format LogFormat  `config:"log_format,optional"`
+	// Output is the path of the file to append log messages to, or
+	// `stdout` or `stderr`.
+	Output string `config:"log_output,optional"`
+}
+
+// LogFormat is the format log messages are written in, written in a config
+// file as `text` or `json`.
+type LogFormat int
+
+const (
+	LogText LogFormat = iota
+	LogJSON
+)
+
+func (f *LogFormat) ParseConfigValue(s string) error {
+	switch s {
+	case "text":
+		*f = LogText
+	case "json":
+		*f = LogJSON
+	default:
+		return fmt.Errorf("unknown log format '%v' (expected one of json, text)", s)
+	}
+
+	return nil
+}
+
+func (f LogFormat) ConfigValue() (string, error) {
+	switch f {
+	case LogText:
+		return "text", nil
+	case LogJSON:
+		return "json", nil
+	default:
+		return "", fmt.Errorf("unknown log format %d", int(f))
+	}
+}
+
+func (f LogFormat) String() string {
+	switch f {
+	case LogText:
+		return "text"
+	case LogJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("LogFormat(%d)", int(f))
+	}
+}
+
+// Handler returns a handler writing to w in the configured format at the
+// configured level.
+func (l *Logging) Handler(w io.Writer) slog.Handler {
+	opts := &slog.HandlerOptions{Level: l.Level}
+	if l.Format == LogJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// Build opens the configured output and returns a logger writing to it. An
+// output file is opened for appending, and is created if it doesn't exist.
+func (l *Logging) Build() (*slog.Logger, error) {
+	var w io.Writer
+	switch l.Output {
+	case "", "stderr":
+		w = os.Stderr
+	case "stdout":
+		w = os.Stdout
+	default:
+		f, err := os.OpenFile(l.Output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("error opening log output: %w", err)
+		}
+		w = f
+	}
+
+	return slog.New(l.Handler(w)), nil
+}
@@ -0,0 +1,59 @@
+package types_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+	"go.eldidi.org/config/types"
+)
+
+func TestSlogLevel(t *testing.T) {
+	var conf struct {
+		LogLevel slog.Level
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	log_level = debug
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.LogLevel != slog.LevelDebug {
+		t.Fatalf("expected DEBUG, found %v", conf.LogLevel)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var conf struct {
+		types.Logging
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	log_level = warn
+	log_format = json
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	var b bytes.Buffer
+	h := conf.Logging.Handler(&b)
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Fatal("expected info messages to be disabled")
+	}
+
+	slog.New(h).Warn("careful")
+	if !strings.HasPrefix(b.String(), "{") {
+		t.Fatalf("expected JSON output, found: %v", b.String())
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	log_format = xml
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
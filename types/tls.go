@@ -0,0 +1,157 @@
+package types
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLS holds the options needed to set up TLS for a server or a client. All of
+// the options are optional, but the certificate and key must be given
+// together.
+type TLS struct {
+	CertFile   string     `config:"tls_cert_file,optional" validate:"file" requiredif:"tls_key_file"`
+	KeyFile    string     `config:"tls_key_file,optional" validate:"file" requiredif:"tls_cert_file"`
+	CAFile     string     `config:"tls_ca_file,optional" validate:"file"`
+	MinVersion TLSVersion `config:"tls_min_version,optional"`
+	ClientAuth ClientAuth `config:"tls_client_auth,optional"`
+}
+
+// Build creates a *tls.Config from the options. If a CA file was given, it is
+// used both to verify servers and to verify client certificates. The minimum
+// version defaults to TLS 1.2.
+func (t *TLS) Build() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.ClientAuthType(t.ClientAuth),
+	}
+
+	if t.MinVersion != 0 {
+		cfg.MinVersion = uint16(t.MinVersion)
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading TLS certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading TLS CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf(
+				"error loading TLS CA file: no certificates found in '%v'",
+				t.CAFile,
+			)
+		}
+		cfg.RootCAs = pool
+		cfg.ClientCAs = pool
+	}
+
+	if cfg.ClientAuth >= tls.VerifyClientCertIfGiven && cfg.ClientCAs == nil {
+		return nil, errors.New("TLS client certificate verification requires a CA file")
+	}
+
+	return cfg, nil
+}
+
+// TLSVersion is a TLS protocol version, written in a config file as `1.0`,
+// `1.1`, `1.2` or `1.3`. An empty value leaves the version unset, so that
+// Build uses its default.
+type TLSVersion uint16
+
+var tlsVersions = map[string]TLSVersion{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func (v *TLSVersion) ParseConfigValue(s string) error {
+	if s == "" {
+		*v = 0
+		return nil
+	}
+
+	version, ok := tlsVersions[s]
+	if !ok {
+		return fmt.Errorf("unknown TLS version '%v' (expected one of 1.0, 1.1, 1.2, 1.3)", s)
+	}
+
+	*v = version
+	return nil
+}
+
+func (v TLSVersion) ConfigValue() (string, error) {
+	if v == 0 {
+		return "", nil
+	}
+
+	for name, version := range tlsVersions {
+		if version == v {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown TLS version 0x%04x", uint16(v))
+}
+
+func (v TLSVersion) String() string {
+	for name, version := range tlsVersions {
+		if version == v {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%04x", uint16(v))
+}
+
+// ClientAuth is the policy for client certificates, written in a config file
+// as `none`, `request`, `require`, `verify_if_given` or `require_and_verify`.
+type ClientAuth tls.ClientAuthType
+
+var clientAuths = map[string]ClientAuth{
+	"none":               ClientAuth(tls.NoClientCert),
+	"request":            ClientAuth(tls.RequestClientCert),
+	"require":            ClientAuth(tls.RequireAnyClientCert),
+	"verify_if_given":    ClientAuth(tls.VerifyClientCertIfGiven),
+	"require_and_verify": ClientAuth(tls.RequireAndVerifyClientCert),
+}
+
+func (c *ClientAuth) ParseConfigValue(s string) error {
+	auth, ok := clientAuths[s]
+	if !ok {
+		return fmt.Errorf(
+			"unknown TLS client auth '%v' (expected one of none, request, require, verify_if_given, require_and_verify)",
+			s,
+		)
+	}
+
+	*c = auth
+	return nil
+}
+
+func (c ClientAuth) ConfigValue() (string, error) {
+	for name, auth := range clientAuths {
+		if auth == c {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown TLS client auth %d", int(c))
+}
+
+func (c ClientAuth) String() string {
+	for name, auth := range clientAuths {
+		if auth == c {
+			return name
+		}
+	}
+	return fmt.Sprintf("ClientAuth(%d)", int(c))
+}
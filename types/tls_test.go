@@ -0,0 +1,77 @@
+package types_test
+
+import (
+	"crypto/tls"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+	"go.eldidi.org/config/types"
+)
+
+func TestTLSEmbedded(t *testing.T) {
+	var conf struct {
+		types.TLS
+		Port int
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	port = 443
+	tls_min_version = 1.3
+	tls_client_auth = request
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	cfg, err := conf.TLS.Build()
+	if err != nil {
+		t.Fatalf("failed to build TLS config: %v", err)
+	}
+
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("expected TLS 1.3, found %v", types.TLSVersion(cfg.MinVersion))
+	}
+
+	if cfg.ClientAuth != tls.RequestClientCert {
+		t.Fatalf("expected request, found %v", types.ClientAuth(cfg.ClientAuth))
+	}
+}
+
+func TestTLSDefaults(t *testing.T) {
+	var conf types.TLS
+	err := config.Read("<input>", strings.NewReader(""), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	cfg, err := conf.Build()
+	if err != nil {
+		t.Fatalf("failed to build TLS config: %v", err)
+	}
+
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("expected TLS 1.2, found %v", types.TLSVersion(cfg.MinVersion))
+	}
+}
+
+func TestTLSInvalid(t *testing.T) {
+	inputs := []string{
+		"tls_min_version = 2.0",
+		"tls_client_auth = sometimes",
+		"tls_cert_file = tls_test.go",
+		"tls_ca_file = does_not_exist.pem",
+	}
+
+	for _, input := range inputs {
+		var conf types.TLS
+		err := config.Read("<input>", strings.NewReader(input), &conf)
+		if err == nil {
+			t.Fatalf("expected error for %q, found no error", input)
+		}
+	}
+
+	conf := types.TLS{ClientAuth: types.ClientAuth(tls.RequireAndVerifyClientCert)}
+	if _, err := conf.Build(); err == nil {
+		t.Fatal("expected error verifying clients without a CA, found no error")
+	}
+}
@@ -0,0 +1,11 @@
+// package types contains ready-made option types and blocks of options for
+// things most services need to configure, for use with [config.Read].
+//
+// The option blocks are meant to be embedded in your own config struct, which
+// makes their options part of your config file:
+//
+//	type Config struct {
+//		types.TLS
+//		Port int
+//	}
+package types
@@ -0,0 +1,298 @@
+package config_test
+
+import (
+	"fmt"
+	"math/big"
+	"net/mail"
+	"net/netip"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"go.eldidi.org/config"
+)
+
+func TestRegexp(t *testing.T) {
+	var conf struct {
+		Filter *regexp.Regexp
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	filter = ^/api/v[0-9]+/
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if !conf.Filter.MatchString("/api/v2/users") {
+		t.Fatalf("expected %v to match", conf.Filter)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	filter = ([a-z]
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	if !strings.Contains(err.Error(), "filter") {
+		t.Fatalf("expected the error to name the key, found: %v", err)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	var conf struct {
+		Route config.Glob
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	route = /static/*.css
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if !conf.Route.Match("/static/site.css") {
+		t.Fatalf("expected %v to match", conf.Route)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	route = /static/[.css
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+type point struct {
+	x, y int
+}
+
+func (p *point) ParseConfigValue(s string) error {
+	_, err := fmt.Sscanf(s, "%d,%d", &p.x, &p.y)
+	return err
+}
+
+func TestValueParser(t *testing.T) {
+	var conf struct {
+		Origin point
+		Target *point
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	origin = 1,2
+	target = 3,4
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Origin != (point{1, 2}) {
+		t.Fatalf("expected {1 2}, found %v", conf.Origin)
+	}
+
+	if conf.Target == nil || *conf.Target != (point{3, 4}) {
+		t.Fatalf("expected &{3 4}, found %v", conf.Target)
+	}
+}
+
+func TestMailAddress(t *testing.T) {
+	var conf struct {
+		From    mail.Address
+		AlertTo []mail.Address
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	from = alerts@example.com
+	alert_to = Ops <ops@example.com>, oncall@example.com
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.From.Address != "alerts@example.com" {
+		t.Fatalf(`expected "alerts@example.com", found "%v"`, conf.From.Address)
+	}
+
+	if len(conf.AlertTo) != 2 {
+		t.Fatalf("expected 2 addresses, found %v: %v", len(conf.AlertTo), conf.AlertTo)
+	}
+
+	if conf.AlertTo[0].Name != "Ops" || conf.AlertTo[0].Address != "ops@example.com" {
+		t.Fatalf(`expected "Ops <ops@example.com>", found "%v"`, conf.AlertTo[0].String())
+	}
+
+	if conf.AlertTo[1].Address != "oncall@example.com" {
+		t.Fatalf(`expected "oncall@example.com", found "%v"`, conf.AlertTo[1].Address)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	from = not an address
+	alert_to = oncall@example.com
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestBig(t *testing.T) {
+	var conf struct {
+		Supply  big.Int
+		ChainID *big.Int `config:"chain_id"`
+		Rate    *big.Float
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	supply = 340282366920938463463374607431768211456
+	chain_id = 0x1
+	rate = 1.000000000000000000000000000001
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Supply.String() != "340282366920938463463374607431768211456" {
+		t.Fatalf("expected 2^128, found %v", conf.Supply.String())
+	}
+
+	if conf.ChainID.Int64() != 1 {
+		t.Fatalf("expected 1, found %v", conf.ChainID)
+	}
+
+	if conf.Rate.Cmp(big.NewFloat(1)) <= 0 {
+		t.Fatalf("expected the precision to be kept, found %v", conf.Rate.Text('g', 40))
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	supply = 12abc
+	chain_id = 1
+	rate = 1
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestTextUnmarshaler(t *testing.T) {
+	var conf struct {
+		Bind netip.Addr
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	bind = 127.0.0.1
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Bind != netip.MustParseAddr("127.0.0.1") {
+		t.Fatalf(`expected "127.0.0.1", found "%v"`, conf.Bind)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	bind = localhost
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestLocation(t *testing.T) {
+	var conf struct {
+		ReportTZ *time.Location `config:"report_tz"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	report_tz = America/New_York
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.ReportTZ.String() != "America/New_York" {
+		t.Fatalf(`expected "America/New_York", found "%v"`, conf.ReportTZ)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	report_tz = Mars/Olympus_Mons
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	if !strings.Contains(err.Error(), "report_tz") {
+		t.Fatalf("expected the error to name the key, found: %v", err)
+	}
+}
+
+// backendRef resolves the name of a backend to its address, which is given by
+// the `backend.<name>` option.
+type backendRef struct {
+	name, addr string
+}
+
+func (b *backendRef) ParseConfigValue(key string, all config.Values) error {
+	addr, ok := all["backend."+all[key]]
+	if !ok {
+		return fmt.Errorf("%v refers to unknown backend '%v'", key, all[key])
+	}
+
+	b.name = all[key]
+	b.addr = addr
+	return nil
+}
+
+func TestContextValueParser(t *testing.T) {
+	var conf struct {
+		Cache backendRef
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	backend.primary = 10.0.0.1:6379
+	cache = primary
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Cache.addr != "10.0.0.1:6379" {
+		t.Fatalf(`expected "10.0.0.1:6379", found "%v"`, conf.Cache.addr)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	cache = secondary
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestComplex(t *testing.T) {
+	var conf struct {
+		Coefficient complex128
+		Gain        complex64
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	coefficient = 0.5-1.25i
+	gain = 2
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	if conf.Coefficient != complex(0.5, -1.25) || conf.Gain != 2 {
+		t.Fatalf("expected (0.5-1.25i) and (2+0i), found %v and %v", conf.Coefficient, conf.Gain)
+	}
+
+	var b strings.Builder
+	if err := config.Write(&b, &conf); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	expected := "coefficient = (0.5-1.25i)\ngain = (2+0i)\n"
+	if b.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, b.String())
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	coefficient = 1+
+	gain = 2
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	validatorsMu sync.RWMutex
+	// validators holds the named validators which can be referenced from
+	// the `validate:""` struct tag.
+	validators = map[string]func(string) error{
+		"url":      validateURL,
+		"hostport": validateHostPort,
+		"email":    validateEmail,
+		"cidr":     validateCIDR,
+		"file":     validateFile,
+	}
+)
+
+// RegisterValidator makes a validator available under the given name, so it
+// can be referenced from the `validate:""` struct tag. The function is given
+// the raw value from the config and should return a non-nil error describing
+// why it isn't acceptable.
+//
+// RegisterValidator panics if fn is nil, if the name is empty or contains a
+// comma, or if a validator with that name already exists.
+func RegisterValidator(name string, fn func(string) error) {
+	if fn == nil {
+		panic("config: RegisterValidator called with nil function")
+	}
+
+	if name == "" || strings.Contains(name, ",") {
+		panic("config: invalid validator name '" + name + "'")
+	}
+
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	if _, dup := validators[name]; dup {
+		panic("config: RegisterValidator called twice for validator '" + name + "'")
+	}
+
+	validators[name] = fn
+}
+
+// validate runs each of the comma separated validators named in tag against
+// val, returning the first error encountered. The `file` validator looks for
+// the file in fsys, or on the host if fsys is nil.
+func validate(tag, val string, fsys fs.FS) error {
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		validatorsMu.RLock()
+		v, ok := validators[name]
+		validatorsMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("unknown validator '%v'", name)
+		}
+
+		if name == "file" {
+			v = func(val string) error { return validateFileFS(fsys, val) }
+		}
+
+		if err := v(val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateURL(val string) error {
+	u, err := url.Parse(val)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("'%v' is not an absolute URL", val)
+	}
+
+	return nil
+}
+
+func validateHostPort(val string) error {
+	_, port, err := net.SplitHostPort(val)
+	if err != nil {
+		return err
+	}
+
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("'%v' is not a valid port", port)
+	}
+
+	return nil
+}
+
+func validateEmail(val string) error {
+	addr, err := mail.ParseAddress(val)
+	if err != nil {
+		return err
+	}
+
+	// ParseAddress also accepts the `Name <user@host>` form, which isn't
+	// what someone asking for an email address wants.
+	if addr.Address != val {
+		return fmt.Errorf("'%v' is not a bare email address", val)
+	}
+
+	return nil
+}
+
+func validateCIDR(val string) error {
+	_, _, err := net.ParseCIDR(val)
+	return err
+}
+
+func validateFile(val string) error {
+	return validateFileFS(nil, val)
+}
+
+// validateFileFS is the `file` validator for a file in fsys, or on the host if
+// fsys is nil, as given to WithFS.
+func validateFileFS(fsys fs.FS, val string) error {
+	var info fs.FileInfo
+	var err error
+	if fsys != nil {
+		info, err = fs.Stat(fsys, val)
+	} else {
+		info, err = os.Stat(val)
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return errors.New("'" + val + "' is a directory")
+	}
+
+	return nil
+}
+
+// The rules which can be given to the `group:""` struct tag.
+const (
+	exactlyOne = "exactlyone"
+	atMostOne  = "atmostone"
+	atLeastOne = "atleastone"
+)
+
+type group struct {
+	rule    string
+	members []string
+	set     []string
+}
+
+// groupSet collects the members of each group declared with the `group:""`
+// struct tag while a struct is being read, so the group rules can be checked
+// once every field has been seen.
+type groupSet map[string]*group
+
+// add records that the option name belongs to the group described by tag,
+// and whether it was present in the config. The group's name is relative to
+// parent, the name of the struct holding the option.
+func (gs groupSet) add(tag, parent, name string, present bool) error {
+	groupName, rule, _ := strings.Cut(tag, ",")
+	groupName = strings.TrimSpace(groupName)
+	rule = strings.TrimSpace(rule)
+	if groupName == "" {
+		return fmt.Errorf("option %v has an empty group name", name)
+	}
+	groupName = joinName(parent, groupName)
+
+	switch rule {
+	case "", exactlyOne, atMostOne, atLeastOne:
+	default:
+		return fmt.Errorf("unknown group rule '%v' for group %v", rule, groupName)
+	}
+
+	g, ok := gs[groupName]
+	if !ok {
+		g = &group{}
+		gs[groupName] = g
+	}
+
+	if rule != "" {
+		if g.rule != "" && g.rule != rule {
+			return fmt.Errorf(
+				"group %v has conflicting rules '%v' and '%v'",
+				groupName, g.rule, rule,
+			)
+		}
+		g.rule = rule
+	}
+
+	g.members = append(g.members, name)
+	if present {
+		g.set = append(g.set, name)
+	}
+
+	return nil
+}
+
+// check returns an error describing every group whose rule isn't satisfied.
+func (gs groupSet) check(path string) error {
+	names := make([]string, 0, len(gs))
+	for name := range gs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		g := gs[name]
+		members := strings.Join(g.members, ", ")
+		found := "none"
+		if len(g.set) > 0 {
+			found = strings.Join(g.set, ", ")
+		}
+
+		var err error
+		kind := KindConstraint
+		switch g.rule {
+		case exactlyOne:
+			if len(g.set) != 1 {
+				err = fmt.Errorf(
+					"exactly one of %v must be set (found %v)",
+					members, found,
+				)
+			}
+		case atMostOne:
+			if len(g.set) > 1 {
+				err = fmt.Errorf(
+					"at most one of %v may be set (found %v)",
+					members, found,
+				)
+			}
+		case atLeastOne:
+			if len(g.set) == 0 {
+				err = fmt.Errorf(
+					"at least one of %v must be set",
+					members,
+				)
+			}
+		default:
+			err = fmt.Errorf("group %v has no rule", name)
+			kind = KindUnsupported
+		}
+
+		if err != nil {
+			errs = append(errs, newError(path, name, kind, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
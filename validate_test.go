@@ -0,0 +1,246 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go.eldidi.org/config"
+)
+
+type validated struct {
+	URL      string `config:"url,optional" validate:"url"`
+	HostPort string `config:"hostport,optional" validate:"hostport"`
+	Email    string `config:"email,optional" validate:"email"`
+	CIDR     string `config:"cidr,optional" validate:"cidr"`
+	File     string `config:"file,optional" validate:"file"`
+}
+
+func TestBuiltinValidators(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		valid bool
+	}{
+		{"url", "https://example.com/path", true},
+		{"url", "example.com", false},
+		{"hostport", "localhost:8080", true},
+		{"hostport", ":8080", true},
+		{"hostport", "localhost", false},
+		{"hostport", "localhost:99999", false},
+		{"email", "ops@example.com", true},
+		{"email", "Ops <ops@example.com>", false},
+		{"cidr", "10.0.0.0/8", true},
+		{"cidr", "10.0.0.0", false},
+		{"file", "validate_test.go", true},
+		{"file", "does_not_exist.conf", false},
+		{"file", ".", false},
+	}
+
+	for _, test := range tests {
+		var conf validated
+		err := config.Read("<input>", strings.NewReader(
+			test.key+" = "+test.value,
+		), &conf, config.WithEnvironment(config.EnvMap{}))
+		if test.valid && err != nil {
+			t.Fatalf("expected '%v' to be a valid %v: %v", test.value, test.key, err)
+		} else if !test.valid && err == nil {
+			t.Fatalf("expected '%v' to be an invalid %v", test.value, test.key)
+		}
+	}
+}
+
+func TestFileValidatorFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/app/cert.pem": {Data: []byte("cert")},
+	}
+
+	var conf validated
+	err := config.Read("<input>", strings.NewReader("file = etc/app/cert.pem"), &conf,
+		config.WithFS(fsys), config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	err = config.Read("<input>", strings.NewReader("file = etc/app"), &conf,
+		config.WithFS(fsys), config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error for a directory, found no error")
+	}
+
+	err = config.Read("<input>", strings.NewReader("file = validate_test.go"), &conf,
+		config.WithFS(fsys), config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error for a file outside the file system, found no error")
+	}
+}
+
+func TestUnknownValidator(t *testing.T) {
+	var conf struct {
+		Value string `validate:"nonsense"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	value = 1
+	`), &conf)
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+	config.RegisterValidator("even", func(val string) error {
+		if len(val)%2 != 0 {
+			return errors.New("odd length")
+		}
+		return nil
+	})
+
+	var conf struct {
+		Value string `validate:"even"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	value = abcd
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	value = abc
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestGroupExactlyOne(t *testing.T) {
+	var conf struct {
+		UnixSocket string `group:"listener,exactlyone"`
+		TCPAddr    string `config:"tcp_addr" group:"listener"`
+	}
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{"", false},
+		{"unix_socket = /run/app.sock", true},
+		{"tcp_addr = :8080", true},
+		{"unix_socket = /run/app.sock\ntcp_addr = :8080", false},
+	}
+
+	for _, test := range tests {
+		err := config.Read("<input>", strings.NewReader(test.input), &conf,
+			config.WithEnvironment(config.EnvMap{}))
+		if test.valid && err != nil {
+			t.Fatalf("expected %q to be valid: %v", test.input, err)
+		} else if !test.valid && err == nil {
+			t.Fatalf("expected %q to be invalid", test.input)
+		}
+	}
+}
+
+func TestGroupErrorsAggregate(t *testing.T) {
+	var conf struct {
+		A string `group:"first,atleastone"`
+		B string `group:"first"`
+		C string `group:"second,atmostone"`
+		D string `group:"second"`
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	c = 1
+	d = 2
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	msg := err.Error()
+	if !strings.Contains(msg, "at least one of a, b") ||
+		!strings.Contains(msg, "at most one of c, d") {
+		t.Fatalf("expected both groups to be reported, found: %v", msg)
+	}
+}
+
+type groupedDB struct {
+	Socket string `group:"listener,exactlyone"`
+	Addr   string `group:"listener"`
+}
+
+func TestGroupNested(t *testing.T) {
+	var conf struct {
+		Primary groupedDB
+		Replica groupedDB
+	}
+	err := config.Read("<input>", strings.NewReader(`
+	primary.socket = /a
+	replica.addr = b:1
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to parse config into struct: %v", err)
+	}
+
+	err = config.Read("<input>", strings.NewReader(`
+	primary.socket = /a
+	primary.addr = a:1
+	replica.addr = b:1
+	`), &conf, config.WithEnvironment(config.EnvMap{}))
+	if err == nil || !strings.Contains(err.Error(), "exactly one of primary.socket, primary.addr must be set") {
+		t.Fatalf("expected only primary's group to fail, found %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type tls struct {
+		Cert string
+		Key  string `config:"key,optional"`
+	}
+	type server struct {
+		Host string
+		Port int `config:"port,optional"`
+		TLS  *tls
+	}
+
+	prototype := server{Port: 8080, TLS: &tls{Cert: "default.pem"}}
+	report, err := config.Validate("<input>", strings.NewReader("host = localhost\ntls.cert = app.pem"), &prototype,
+		config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to validate config: %v", err)
+	}
+
+	if report.Values["tls.cert"] != "app.pem" || len(report.Fields) != 4 {
+		t.Fatalf("expected a report of the read, found %+v", report)
+	}
+
+	if prototype.Host != "" || prototype.TLS.Cert != "default.pem" {
+		t.Fatalf("expected the prototype to be unchanged, found %+v %+v", prototype, *prototype.TLS)
+	}
+
+	_, err = config.Validate("<input>", strings.NewReader("port = 80"), &prototype,
+		config.WithEnvironment(config.EnvMap{}))
+	errs := config.Errors(err)
+	if len(errs) != 1 || errs[0].Key != "host" || errs[0].Kind != config.KindMissing {
+		t.Fatalf("expected host to be missing, found %v", err)
+	}
+	named := struct {
+		N *validateName `config:"n"`
+	}{N: &validateName{"orig"}}
+	_, err = config.Validate("<input>", strings.NewReader("n = changed"), &named,
+		config.WithEnvironment(config.EnvMap{}))
+	if err != nil {
+		t.Fatalf("failed to validate config: %v", err)
+	}
+
+	if named.N.name != "orig" {
+		t.Fatalf(`expected "orig", found "%v"`, named.N.name)
+	}
+}
+
+type validateName struct {
+	name string
+}
+
+func (n *validateName) ParseConfigValue(s string) error {
+	n.name = s
+	return nil
+}
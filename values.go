@@ -0,0 +1,108 @@
+package config
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Match returns the options in v whose keys match pattern, in the syntax of
+// path.Match. Unlike in file paths, `*` also matches dots, so `db.*.host`
+// matches `db.primary.host` as well as `db.eu.primary.host`.
+func (v Values) Match(pattern string) (Values, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	out := Values{}
+	for key, val := range v {
+		if ok, _ := path.Match(pattern, key); ok {
+			out[key] = val
+		}
+	}
+	return out, nil
+}
+
+// WithPrefix returns the options in v whose keys start with prefix, with the
+// prefix removed from the keys. For example, `WithPrefix("feature_flags.")`
+// turns `feature_flags.new_ui` into `new_ui`.
+func (v Values) WithPrefix(prefix string) Values {
+	out := Values{}
+	for key, val := range v {
+		if rest, ok := strings.CutPrefix(key, prefix); ok {
+			out[rest] = val
+		}
+	}
+	return out
+}
+
+// Overlay is a read-only view of several Values layered on top of each other,
+// as returned by [config.NewOverlay]. Looking up a key finds its value in the
+// topmost layer which has it. No layer is copied, so overriding a few options
+// of a large base per tenant or per request is cheap. The layers mustn't be
+// changed while the Overlay is in use.
+type Overlay struct {
+	// layers holds the Values, from the bottom up.
+	layers []Values
+}
+
+// NewOverlay returns a view of base with each of patches on top of it, in
+// order, so a later patch overrides an earlier one.
+func NewOverlay(base Values, patches ...Values) Overlay {
+	layers := make([]Values, 0, 1+len(patches))
+	layers = append(layers, base)
+	return Overlay{layers: append(layers, patches...)}
+}
+
+// With returns a view of o with patch on top of it. o is left as it was.
+func (o Overlay) With(patch Values) Overlay {
+	layers := make([]Values, len(o.layers), len(o.layers)+1)
+	copy(layers, o.layers)
+	return Overlay{layers: append(layers, patch)}
+}
+
+// Lookup returns the value of key in the topmost layer which has it, and
+// whether any layer does.
+func (o Overlay) Lookup(key string) (string, bool) {
+	for i := len(o.layers) - 1; i >= 0; i -= 1 {
+		if val, ok := o.layers[i][key]; ok {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+// Get returns the value of key, or an empty string if no layer has it.
+func (o Overlay) Get(key string) string {
+	val, _ := o.Lookup(key)
+	return val
+}
+
+// Keys returns the keys in any of the layers, sorted.
+func (o Overlay) Keys() []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, layer := range o.layers {
+		for key := range layer {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Values returns the options o holds as one Values, for reading them into a
+// struct with [config.ReadSection]. Unlike the Overlay itself, this copies
+// every option.
+func (o Overlay) Values() Values {
+	out := Values{}
+	for _, layer := range o.layers {
+		for key, val := range layer {
+			out[key] = val
+		}
+	}
+	return out
+}
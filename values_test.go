@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestValuesQueries(t *testing.T) {
+	vals, err := config.Parse("<input>", strings.NewReader(`
+	db.primary.host = db1
+	db.replica.host = db2
+	db.primary.port = 5432
+	feature_flags.new_ui = true
+	feature_flags.dark_mode = false
+	`))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	hosts, err := vals.Match("db.*.host")
+	if err != nil {
+		t.Fatalf("failed to match: %v", err)
+	}
+
+	if len(hosts) != 2 || hosts["db.primary.host"] != "db1" || hosts["db.replica.host"] != "db2" {
+		t.Fatalf("expected both hosts, found %v", hosts)
+	}
+
+	if _, err := vals.Match("db.[.host"); err == nil {
+		t.Fatal("expected error, found no error")
+	}
+
+	flags := vals.WithPrefix("feature_flags.")
+	if len(flags) != 2 || flags["new_ui"] != "true" || flags["dark_mode"] != "false" {
+		t.Fatalf("expected both flags, found %v", flags)
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	base := config.Values{"host": "localhost", "port": "80", "debug": "false"}
+	tenant := config.NewOverlay(base, config.Values{"port": "8080"})
+	request := tenant.With(config.Values{"debug": "true"})
+
+	if tenant.Get("port") != "8080" || tenant.Get("debug") != "false" || tenant.Get("host") != "localhost" {
+		t.Fatalf("expected the tenant's port over the base, found %v", tenant.Values())
+	}
+
+	if request.Get("debug") != "true" || request.Get("port") != "8080" {
+		t.Fatalf("expected the request's debug over the tenant, found %v", request.Values())
+	}
+
+	if _, ok := request.Lookup("missing"); ok {
+		t.Fatal("expected missing not to be found")
+	}
+
+	if keys := request.Keys(); !slices.Equal(keys, []string{"debug", "host", "port"}) {
+		t.Fatalf("expected [debug host port], found %v", keys)
+	}
+
+	expected := config.Values{"host": "localhost", "port": "8080", "debug": "true"}
+	if vals := request.Values(); !maps.Equal(vals, expected) {
+		t.Fatalf("expected %v, found %v", expected, vals)
+	}
+
+	if base["port"] != "80" {
+		t.Fatalf(`expected the base to be unchanged, found "%v"`, base["port"])
+	}
+}
@@ -0,0 +1,373 @@
+// package viper provides a subset of the API of github.com/spf13/viper on top
+// of [config], to make migrating from viper possible one file at a time:
+// changing the import path is enough for code which only uses the functions
+// provided here.
+//
+// Keys are case-insensitive. A value is looked up, in order of precedence,
+// from [Set], environment variables (see [BindEnv] and [AutomaticEnv]), the
+// config file, and finally [SetDefault].
+package viper
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.eldidi.org/config"
+)
+
+// Viper holds a set of configuration values and where to look them up.
+type Viper struct {
+	mu           sync.RWMutex
+	configFile   string
+	envPrefix    string
+	automaticEnv bool
+	overrides    map[string]any
+	env          map[string][]string
+	file         config.Values
+	defaults     map[string]any
+	// environ and fsys are set by SetEnvironment and SetFS, and are nil
+	// for the process's environment and the host's files.
+	environ config.Environment
+	fsys    fs.FS
+}
+
+// New returns an empty Viper.
+func New() *Viper {
+	return &Viper{
+		overrides: map[string]any{},
+		env:       map[string][]string{},
+		file:      config.Values{},
+		defaults:  map[string]any{},
+	}
+}
+
+var v = New()
+
+// GetViper returns the Viper used by the package-level functions.
+func GetViper() *Viper {
+	return v
+}
+
+// SetConfigFile sets the path of the file ReadInConfig reads.
+func (v *Viper) SetConfigFile(path string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.configFile = path
+}
+
+// SetFS makes ReadInConfig read the config file from fsys instead of the
+// host's files. It isn't part of viper's API.
+func (v *Viper) SetFS(fsys fs.FS) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fsys = fsys
+}
+
+// SetEnvironment makes environment variables be looked up in env instead of
+// the process's environment, as [config.WithEnvironment] does. It isn't part
+// of viper's API.
+func (v *Viper) SetEnvironment(env config.Environment) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.environ = env
+}
+
+// ReadInConfig reads the file given to SetConfigFile, replacing any values
+// read from a file before.
+func (v *Viper) ReadInConfig() error {
+	v.mu.RLock()
+	path, fsys := v.configFile, v.fsys
+	v.mu.RUnlock()
+	if path == "" {
+		return fmt.Errorf("viper: no config file set")
+	}
+
+	var opts []config.Option
+	if fsys != nil {
+		opts = append(opts, config.WithFS(fsys))
+	}
+	vals, err := config.ParseFiles([]string{path}, opts...)
+	if err != nil {
+		return err
+	}
+
+	v.setFile(vals)
+	return nil
+}
+
+// ReadConfig reads the values of a config file from r, replacing any values
+// read from a file before.
+func (v *Viper) ReadConfig(r io.Reader) error {
+	vals, err := config.Parse("<input>", r)
+	if err != nil {
+		return err
+	}
+
+	v.setFile(vals)
+	return nil
+}
+
+func (v *Viper) setFile(vals config.Values) {
+	file := make(config.Values, len(vals))
+	for key, val := range vals {
+		file[strings.ToLower(key)] = val
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.file = file
+}
+
+// SetDefault sets the value used for key when it isn't set anywhere else.
+func (v *Viper) SetDefault(key string, value any) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.defaults[strings.ToLower(key)] = value
+}
+
+// Set sets the value of key, overriding every other source.
+func (v *Viper) Set(key string, value any) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.overrides[strings.ToLower(key)] = value
+}
+
+// SetEnvPrefix sets the prefix of the environment variables looked up for
+// keys bound without an explicit variable name, and by AutomaticEnv.
+func (v *Viper) SetEnvPrefix(prefix string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.envPrefix = prefix
+}
+
+// AutomaticEnv makes every key be looked up in the environment, as if
+// BindEnv had been called for it.
+func (v *Viper) AutomaticEnv() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.automaticEnv = true
+}
+
+// BindEnv binds a key to environment variables. The first argument is the key,
+// and the rest are the names of the variables to check, in order. If no names
+// are given, the key in upper case is used, after the prefix from
+// SetEnvPrefix.
+func (v *Viper) BindEnv(input ...string) error {
+	if len(input) == 0 {
+		return fmt.Errorf("viper: BindEnv missing key to bind to")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key := strings.ToLower(input[0])
+	if len(input) == 1 {
+		v.env[key] = []string{v.envName(key)}
+	} else {
+		v.env[key] = input[1:]
+	}
+	return nil
+}
+
+// envName returns the environment variable for key. v.mu must be held.
+func (v *Viper) envName(key string) string {
+	name := strings.ToUpper(key)
+	if v.envPrefix != "" {
+		name = strings.ToUpper(v.envPrefix) + "_" + name
+	}
+	return name
+}
+
+// Get returns the value of key, or nil if it isn't set. Values from the
+// config file or the environment are strings; values from Set and SetDefault
+// are returned as they were given.
+func (v *Viper) Get(key string) any {
+	val, _ := v.find(strings.ToLower(key))
+	return val
+}
+
+// IsSet reports whether key has a value, from any source.
+func (v *Viper) IsSet(key string) bool {
+	_, ok := v.find(strings.ToLower(key))
+	return ok
+}
+
+func (v *Viper) find(key string) (any, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if val, ok := v.overrides[key]; ok {
+		return val, true
+	}
+
+	names := v.env[key]
+	if names == nil && v.automaticEnv {
+		names = []string{v.envName(key)}
+	}
+	lookupEnv := os.LookupEnv
+	if v.environ != nil {
+		lookupEnv = v.environ.LookupEnv
+	}
+	for _, name := range names {
+		if val, ok := lookupEnv(name); ok {
+			return val, true
+		}
+	}
+
+	if val, ok := v.file[key]; ok {
+		return val, true
+	}
+
+	val, ok := v.defaults[key]
+	return val, ok
+}
+
+// GetString returns the value of key as a string.
+func (v *Viper) GetString(key string) string {
+	val := v.Get(key)
+	if val == nil {
+		return ""
+	}
+	return fmt.Sprint(val)
+}
+
+// GetBool returns the value of key as a bool, or false if it isn't one.
+func (v *Viper) GetBool(key string) bool {
+	if b, ok := v.Get(key).(bool); ok {
+		return b
+	}
+
+	b, _ := strconv.ParseBool(v.GetString(key))
+	return b
+}
+
+// GetInt returns the value of key as an int, or 0 if it isn't one.
+func (v *Viper) GetInt(key string) int {
+	if i, ok := v.Get(key).(int); ok {
+		return i
+	}
+
+	i, _ := strconv.ParseInt(v.GetString(key), 0, 0)
+	return int(i)
+}
+
+// GetFloat64 returns the value of key as a float64, or 0 if it isn't one.
+func (v *Viper) GetFloat64(key string) float64 {
+	if f, ok := v.Get(key).(float64); ok {
+		return f
+	}
+
+	f, _ := strconv.ParseFloat(v.GetString(key), 64)
+	return f
+}
+
+// GetDuration returns the value of key as a time.Duration, or 0 if it isn't
+// one.
+func (v *Viper) GetDuration(key string) time.Duration {
+	if d, ok := v.Get(key).(time.Duration); ok {
+		return d
+	}
+
+	d, _ := time.ParseDuration(v.GetString(key))
+	return d
+}
+
+// AllKeys returns every key with a value from Set, the config file or
+// SetDefault, and every key bound with BindEnv, sorted.
+func (v *Viper) AllKeys() []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	seen := map[string]bool{}
+	for key := range v.overrides {
+		seen[key] = true
+	}
+	for key := range v.env {
+		seen[key] = true
+	}
+	for key := range v.file {
+		seen[key] = true
+	}
+	for key := range v.defaults {
+		seen[key] = true
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Unmarshal sets the fields of the struct obj points to from the current
+// values, as [config.Read] would from a file.
+func (v *Viper) Unmarshal(obj any) error {
+	vals := config.Values{}
+	for _, key := range v.AllKeys() {
+		if val, ok := v.find(key); ok {
+			vals[key] = fmt.Sprint(val)
+		}
+	}
+
+	b, err := config.NewBinder(obj)
+	if err != nil {
+		return err
+	}
+	return b.Bind(vals, obj)
+}
+
+// SetConfigFile calls [Viper.SetConfigFile] on the global Viper.
+func SetConfigFile(path string) { v.SetConfigFile(path) }
+
+// ReadInConfig calls [Viper.ReadInConfig] on the global Viper.
+func ReadInConfig() error { return v.ReadInConfig() }
+
+// ReadConfig calls [Viper.ReadConfig] on the global Viper.
+func ReadConfig(r io.Reader) error { return v.ReadConfig(r) }
+
+// SetDefault calls [Viper.SetDefault] on the global Viper.
+func SetDefault(key string, value any) { v.SetDefault(key, value) }
+
+// Set calls [Viper.Set] on the global Viper.
+func Set(key string, value any) { v.Set(key, value) }
+
+// SetEnvPrefix calls [Viper.SetEnvPrefix] on the global Viper.
+func SetEnvPrefix(prefix string) { v.SetEnvPrefix(prefix) }
+
+// AutomaticEnv calls [Viper.AutomaticEnv] on the global Viper.
+func AutomaticEnv() { v.AutomaticEnv() }
+
+// BindEnv calls [Viper.BindEnv] on the global Viper.
+func BindEnv(input ...string) error { return v.BindEnv(input...) }
+
+// Get calls [Viper.Get] on the global Viper.
+func Get(key string) any { return v.Get(key) }
+
+// IsSet calls [Viper.IsSet] on the global Viper.
+func IsSet(key string) bool { return v.IsSet(key) }
+
+// GetString calls [Viper.GetString] on the global Viper.
+func GetString(key string) string { return v.GetString(key) }
+
+// GetBool calls [Viper.GetBool] on the global Viper.
+func GetBool(key string) bool { return v.GetBool(key) }
+
+// GetInt calls [Viper.GetInt] on the global Viper.
+func GetInt(key string) int { return v.GetInt(key) }
+
+// GetFloat64 calls [Viper.GetFloat64] on the global Viper.
+func GetFloat64(key string) float64 { return v.GetFloat64(key) }
+
+// GetDuration calls [Viper.GetDuration] on the global Viper.
+func GetDuration(key string) time.Duration { return v.GetDuration(key) }
+
+// AllKeys calls [Viper.AllKeys] on the global Viper.
+func AllKeys() []string { return v.AllKeys() }
+
+// Unmarshal calls [Viper.Unmarshal] on the global Viper.
+func Unmarshal(obj any) error { return v.Unmarshal(obj) }
@@ -0,0 +1,97 @@
+package viper_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"go.eldidi.org/config"
+	"go.eldidi.org/config/viper"
+)
+
+func TestPrecedence(t *testing.T) {
+	t.Setenv("MYAPP_HOST", "env.example.com")
+
+	v := viper.New()
+	v.SetEnvPrefix("myapp")
+	v.SetDefault("port", 80)
+	v.SetDefault("timeout", 5*time.Second)
+	v.SetDefault("host", "localhost")
+	if err := v.BindEnv("host"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := v.ReadConfig(strings.NewReader(`
+	port = 8080
+	host = file.example.com
+	debug = true
+	`))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	if v.GetInt("port") != 8080 {
+		t.Fatalf("expected 8080, found %v", v.Get("port"))
+	}
+
+	if v.GetString("host") != "env.example.com" {
+		t.Fatalf(`expected "env.example.com", found "%v"`, v.Get("host"))
+	}
+
+	if !v.GetBool("DEBUG") {
+		t.Fatalf("expected true, found %v", v.Get("debug"))
+	}
+
+	if v.GetDuration("timeout") != 5*time.Second {
+		t.Fatalf("expected 5s, found %v", v.Get("timeout"))
+	}
+
+	v.Set("port", 9090)
+	if v.GetInt("port") != 9090 {
+		t.Fatalf("expected 9090, found %v", v.Get("port"))
+	}
+
+	if v.IsSet("missing") {
+		t.Fatal("expected missing key not to be set")
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	v := viper.New()
+	v.SetDefault("port", 80)
+	v.Set("host", "localhost")
+
+	var conf struct {
+		Host string
+		Port int
+	}
+	if err := v.Unmarshal(&conf); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if conf.Host != "localhost" || conf.Port != 80 {
+		t.Fatalf("expected {localhost 80}, found %+v", conf)
+	}
+}
+
+func TestSetFSAndEnvironment(t *testing.T) {
+	t.Setenv("HOST", "process.example.com")
+
+	v := viper.New()
+	v.SetFS(fstest.MapFS{"app.conf": {Data: []byte("port = 8080\nhost = file.example.com")}})
+	v.SetEnvironment(config.EnvMap{"PORT": "9090"})
+	v.AutomaticEnv()
+	v.SetConfigFile("app.conf")
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	if v.GetInt("port") != 9090 {
+		t.Fatalf("expected 9090, found %v", v.Get("port"))
+	}
+
+	if v.GetString("host") != "file.example.com" {
+		t.Fatalf(`expected "file.example.com", found "%v"`, v.Get("host"))
+	}
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Question asks for the value of an option, as given to a [Prompter] by
+// [config.Wizard].
+type Question struct {
+	Key  string
+	Type reflect.Type
+	// Description is the text of the field's `comment:""` struct tag.
+	Description string
+	// Default is the value used if the answer is empty, or empty if there
+	// is none.
+	Default string
+	// Secret means the answer shouldn't be echoed.
+	Secret bool
+	// Problem is why the last answer to the question was rejected, or
+	// empty the first time it's asked.
+	Problem string
+}
+
+// Prompter is the interface implemented by user interfaces which can ask for
+// the values of options, for [config.Wizard].
+type Prompter interface {
+	// Prompt asks q and returns the answer, which is empty to take the
+	// default.
+	Prompt(q Question) (string, error)
+}
+
+// LinePrompter is a [Prompter] which writes each question on a line of its
+// own, and reads a line as the answer.
+type LinePrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// ReadSecret reads the answer to a secret question without echoing
+	// it, for example by wrapping golang.org/x/term's ReadPassword. If it
+	// is nil, secrets are read like any other answer.
+	ReadSecret func() (string, error)
+}
+
+// NewLinePrompter returns a LinePrompter which reads answers from in and
+// writes questions to out, such as os.Stdin and os.Stdout.
+func NewLinePrompter(in io.Reader, out io.Writer) *LinePrompter {
+	return &LinePrompter{in: bufio.NewReader(in), out: out}
+}
+
+func (p *LinePrompter) Prompt(q Question) (string, error) {
+	if q.Problem != "" {
+		fmt.Fprintf(p.out, "%v\n", q.Problem)
+	} else if q.Description != "" {
+		fmt.Fprintf(p.out, "# %v\n", q.Description)
+	}
+
+	fmt.Fprintf(p.out, "%v (%v)", q.Key, q.Type)
+	if q.Default != "" && !q.Secret {
+		fmt.Fprintf(p.out, " [%v]", q.Default)
+	}
+	fmt.Fprint(p.out, ": ")
+
+	if q.Secret && p.ReadSecret != nil {
+		answer, err := p.ReadSecret()
+		fmt.Fprintln(p.out)
+		return answer, err
+	}
+
+	answer, err := p.in.ReadString('\n')
+	if errors.Is(err, io.EOF) && answer != "" {
+		err = nil
+	}
+	return strings.TrimSpace(answer), err
+}
+
+// Wizard asks p for the value of each required option of the struct obj
+// points to, reads the answers into the struct, and writes it to w as
+// [config.Write] does, giving a config file which can be read straight away.
+// It's meant for the first run of a program, when there is no config file
+// yet.
+//
+// Options which are optional, in a group or required only under a condition
+// aren't asked for, and keep the values they have in the struct. The value an
+// option has in the struct, if it isn't the zero value, is offered as the
+// default. An answer which isn't valid for the option is rejected, and the
+// question asked again.
+func Wizard(w io.Writer, obj any, p Prompter) error {
+	v, err := structValue(obj)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]reflect.Value{}
+	for _, fi := range structFields(v) {
+		current[fi.name] = fi.v
+	}
+
+	vals := Values{}
+	for _, fi := range typeFields(v.Type()) {
+		_, group := fi.f.Tag.Lookup("group")
+		_, requiredif := fi.f.Tag.Lookup("requiredif")
+		if fi.optional || group || requiredif {
+			continue
+		}
+
+		q := Question{
+			Key:         fi.name,
+			Type:        fi.f.Type,
+			Description: fi.f.Tag.Get("comment"),
+			Secret:      fi.secret,
+		}
+		if field, ok := current[fi.name]; ok && !field.IsZero() {
+			if q.Default, err = formatValue(field); err != nil {
+				return fmt.Errorf(errorWritingConfig, fi.name, err)
+			}
+		}
+
+		for {
+			answer, err := p.Prompt(q)
+			if err != nil {
+				return err
+			}
+
+			if answer == "" {
+				answer = q.Default
+			}
+			if q.Problem = checkAnswer(fi, answer, vals); q.Problem == "" {
+				vals[fi.name] = answer
+				break
+			}
+		}
+	}
+
+	o := newOptions([]Option{WithPrecedence(LayerFile)})
+	err = read("<wizard>", func() ([]parsedFile, error) {
+		return []parsedFile{{path: "<wizard>", vals: vals}}, nil
+	}, obj, o)
+	if err != nil {
+		return err
+	}
+	return Write(w, obj)
+}
+
+// checkAnswer returns why answer isn't a valid value for fi, or an empty
+// string if it is.
+func checkAnswer(fi fieldInfo, answer string, vals Values) string {
+	if answer == "" {
+		return fmt.Sprintf("%v is required", fi.name)
+	}
+
+	if tag := fi.f.Tag.Get("validate"); tag != "" {
+		if err := validate(tag, answer, nil); err != nil {
+			return fmt.Sprintf("invalid value for %v: %v", fi.name, err)
+		}
+	}
+
+	if fi.setter {
+		return ""
+	}
+
+	if err := readField(fi.name, answer, vals, reflect.New(fi.f.Type).Elem()); err != nil {
+		return fmt.Sprintf("invalid value for %v: %v", fi.name, err)
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+func TestWizard(t *testing.T) {
+	conf := struct {
+		Host     string `comment:"Host name to listen on"`
+		Port     int
+		Password string `config:"password,secret"`
+		Debug    bool   `config:"debug,optional"`
+	}{Port: 8080}
+
+	var out strings.Builder
+	p := config.NewLinePrompter(strings.NewReader("\nexample.com\neighty\n\nhunter2\n"), &out)
+	p.ReadSecret = func() (string, error) {
+		return "s3cret", nil
+	}
+
+	var file strings.Builder
+	if err := config.Wizard(&file, &conf, p); err != nil {
+		t.Fatalf("failed to run wizard: %v", err)
+	}
+
+	expected := "# Host name to listen on\n" +
+		"host (string): host is required\n" +
+		"host (string): port (int) [8080]: invalid value for port: strconv.ParseInt: parsing \"eighty\": invalid syntax\n" +
+		"port (int) [8080]: password (string): \n"
+	if out.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, out.String())
+	}
+
+	expected = "# Host name to listen on\nhost = example.com\nport = 8080\npassword = s3cret\ndebug = false\n"
+	if file.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, file.String())
+	}
+}
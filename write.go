@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bufio"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValueWriter is the interface implemented by types that can write a text
+// description of themselves. It is the counterpart to [config.ValueParser]
+// used by [config.Write].
+type ValueWriter interface {
+	ConfigValue() (string, error)
+}
+
+var (
+	valueWriterType   = reflect.TypeFor[ValueWriter]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+var (
+	errUnrepresentable = errors.New("value contains both kinds of quotes")
+	errMultilineValue  = errors.New("value contains a line break")
+)
+
+const (
+	errorWritingConfig = "error writing config: %v: %w"
+	unsupportedToWrite = "attempted to write unsupported type '%v' (hint: it doesn't implement config.ValueWriter or encoding.TextMarshaler)"
+)
+
+// Write writes the options in the struct pointed to by obj to w, in the format
+// read by [config.Read], so that reading the output back gives the same
+// values. Nil pointers are left out, since they have no value to write.
+//
+// The text of a field's `comment:""` struct tag is written as a comment above
+// its option.
+func Write(w io.Writer, obj any) error {
+	v, err := structValue(obj)
+	if err != nil {
+		return err
+	}
+
+	b := bufio.NewWriter(w)
+	if err := writeStruct(b, v); err != nil {
+		return err
+	}
+	return b.Flush()
+}
+
+// WriteValues writes vals to w in the format read by [config.Parse], one
+// option per line, sorted by key.
+func WriteValues(w io.Writer, vals Values) error {
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	b := bufio.NewWriter(w)
+	for _, key := range keys {
+		val, err := quote(vals[key])
+		if err != nil {
+			return fmt.Errorf(errorWritingConfig, key, err)
+		}
+
+		if _, err := fmt.Fprintf(b, "%v = %v\n", key, val); err != nil {
+			return err
+		}
+	}
+	return b.Flush()
+}
+
+func writeStruct(w *bufio.Writer, v reflect.Value) error {
+	for _, fi := range structFields(v) {
+		field, name := fi.v, fi.name
+		if field.Kind() == reflect.Pointer && field.IsNil() {
+			continue
+		}
+
+		val, err := formatValue(field)
+		if err != nil {
+			return fmt.Errorf(errorWritingConfig, name, err)
+		}
+		val = formatNumber(fi, val)
+
+		val, err = quote(val)
+		if err != nil {
+			return fmt.Errorf(errorWritingConfig, name, err)
+		}
+
+		if comment := fi.f.Tag.Get("comment"); comment != "" {
+			for _, line := range strings.Split(comment, "\n") {
+				if _, err := fmt.Fprintf(w, "# %v\n", line); err != nil {
+					return err
+				}
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%v = %v\n", name, val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatValue returns the text form of field, which is the inverse of the
+// conversion Read does.
+func formatValue(field reflect.Value) (string, error) {
+	if w, ok := writerImplementation(field, valueWriterType); ok {
+		return w.(ValueWriter).ConfigValue()
+	}
+
+	if m, ok := writerImplementation(field, textMarshalerType); ok {
+		text, err := m.(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	if format, ok := lookupFormatter(field.Type()); ok {
+		return format(field.Interface()), nil
+	}
+
+	if names, ok := lookupEnum(field.Type()); ok {
+		if name, ok := formatEnum(names, field); ok {
+			return name, nil
+		}
+	}
+
+	if format, ok := kindFormatters[field.Kind()]; ok {
+		return format(field), nil
+	}
+
+	return "", fmt.Errorf(unsupportedToWrite, field.Type().String())
+}
+
+// writerImplementation returns field as an implementation of iface, if it
+// implements it either directly or through a pointer.
+func writerImplementation(field reflect.Value, iface reflect.Type) (any, bool) {
+	if field.Type().Implements(iface) {
+		return field.Interface(), true
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(iface) {
+		return field.Addr().Interface(), true
+	}
+
+	return nil, false
+}
+
+// quote encloses val in quotes if it wouldn't be read back as-is otherwise.
+func quote(val string) (string, error) {
+	if strings.ContainsAny(val, "\r\n") {
+		return "", errMultilineValue
+	}
+
+	needsQuotes := val == "" ||
+		strings.ContainsRune(val, '#') ||
+		strings.TrimSpace(val) != val ||
+		val[0] == '"' || val[0] == '\''
+	if !needsQuotes {
+		return val, nil
+	}
+
+	switch {
+	case !strings.ContainsRune(val, '"'):
+		return `"` + val + `"`, nil
+	case !strings.ContainsRune(val, '\''):
+		return "'" + val + "'", nil
+	default:
+		return "", errUnrepresentable
+	}
+}
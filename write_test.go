@@ -0,0 +1,163 @@
+package config_test
+
+import (
+	"fmt"
+	"math/big"
+	"net/mail"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go.eldidi.org/config"
+)
+
+// celsius writes itself with a unit, and parses the same form back.
+type celsius float64
+
+func (c *celsius) ParseConfigValue(s string) error {
+	_, err := fmt.Sscanf(s, "%gC", (*float64)(c))
+	return err
+}
+
+func (c celsius) ConfigValue() (string, error) {
+	return fmt.Sprintf("%gC", float64(c)), nil
+}
+
+type roundTrip struct {
+	Name    string
+	Port    int
+	Ratio   float64
+	Debug   bool
+	MaxTemp celsius
+	Filter  *regexp.Regexp
+	Owner   mail.Address
+	Supply  *big.Int
+	Format  logFormat
+	Unset   *big.Int `config:"unset,optional"`
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	config.RegisterEnum(map[string]logFormat{
+		"text": logText,
+		"json": logJSON,
+	})
+
+	supply, _ := new(big.Int).SetString("340282366920938463463374607431768211456", 10)
+	in := roundTrip{
+		Name:    "api # primary",
+		Port:    8080,
+		Ratio:   0.25,
+		Debug:   true,
+		MaxTemp: 85.5,
+		Filter:  regexp.MustCompile(`^/api/`),
+		Owner:   mail.Address{Name: "Ops", Address: "ops@example.com"},
+		Supply:  supply,
+		Format:  logJSON,
+	}
+
+	var b strings.Builder
+	if err := config.Write(&b, &in); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if strings.Contains(b.String(), "unset") {
+		t.Fatalf("expected nil pointer to be left out, found:\n%v", b.String())
+	}
+
+	var out roundTrip
+	if err := config.Read("<input>", strings.NewReader(b.String()), &out,
+		config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read written config: %v\n%v", err, b.String())
+	}
+
+	if out.Name != in.Name || out.Port != in.Port || out.Ratio != in.Ratio ||
+		out.Debug != in.Debug || out.MaxTemp != in.MaxTemp ||
+		out.Filter.String() != in.Filter.String() || out.Owner != in.Owner ||
+		out.Supply.Cmp(in.Supply) != 0 || out.Format != in.Format {
+		t.Fatalf("expected %+v, found %+v", in, out)
+	}
+}
+
+func TestWriteWhitespace(t *testing.T) {
+	in := struct {
+		Password  string
+		Separator string
+	}{Password: "  hunter2 ", Separator: " | "}
+
+	var b strings.Builder
+	if err := config.Write(&b, &in); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	expected := "password = \"  hunter2 \"\nseparator = \" | \"\n"
+	if b.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, b.String())
+	}
+
+	out := in
+	out.Password, out.Separator = "", ""
+	if err := config.Read("<input>", strings.NewReader(b.String()), &out,
+		config.WithEnvironment(config.EnvMap{})); err != nil {
+		t.Fatalf("failed to read written config: %v", err)
+	}
+
+	if out != in {
+		t.Fatalf("expected %q, found %q", in, out)
+	}
+
+	vals, err := config.Parse("<input>", strings.NewReader("a =   x  \nb = '  x  '  # comment"))
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	if vals["a"] != "x" || vals["b"] != "  x  " {
+		t.Fatalf(`expected "x" and "  x  ", found %q and %q`, vals["a"], vals["b"])
+	}
+}
+
+func TestWriteUnrepresentable(t *testing.T) {
+	conf := struct {
+		Value string
+	}{`both ' and " # here`}
+
+	var b strings.Builder
+	if err := config.Write(&b, &conf); err == nil {
+		t.Fatal("expected error, found no error")
+	}
+}
+
+func TestWriteComment(t *testing.T) {
+	conf := struct {
+		Port int    `comment:"The port to listen on."`
+		Host string `comment:"The address to bind to.\nLeave empty for all interfaces."`
+	}{8080, "localhost"}
+
+	var b strings.Builder
+	if err := config.Write(&b, &conf); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	expected := `# The port to listen on.
+port = 8080
+# The address to bind to.
+# Leave empty for all interfaces.
+host = localhost
+`
+	if b.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, b.String())
+	}
+}
+
+func TestWriteValues(t *testing.T) {
+	vals := config.Values{"port": "8080", "greeting": " hi "}
+
+	var b strings.Builder
+	if err := config.WriteValues(&b, vals); err != nil {
+		t.Fatalf("failed to write values: %v", err)
+	}
+
+	expected := "greeting = \" hi \"\nport = 8080\n"
+	if b.String() != expected {
+		t.Fatalf("expected:\n%v\nfound:\n%v", expected, b.String())
+	}
+}